@@ -0,0 +1,289 @@
+// Package api lets scripts play Connectron over a local HTTP API. Every
+// reply is JSON; errors look like {"error": "column is full", "code":
+// "column_full"} with a matching status code.
+//
+//	POST   /api/games                 create a game; the body is a types.GameConfig
+//	GET    /api/games                 list the games
+//	GET    /api/games/{id}            the game's settings and board
+//	DELETE /api/games/{id}            forget a game
+//	GET    /api/games/{id}/moves      every move of the series so far
+//	POST   /api/games/{id}/moves      play {"column": 3, "bomb": false} for the current player
+//	POST   /api/games/{id}/ai-move    let the AI play for the current player (?level=hard to choose)
+//	POST   /api/games/{id}/next-round start the next round once one is over
+//	GET    /api/leaderboard           the leaderboard, one object per player
+//
+// Columns, seats and grid cells count from 0, as in saved games. Nothing
+// plays by itself: when it is an AI's turn, ask for an AI move.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// DefaultAddr only listens on this computer, since the API has no logins.
+const DefaultAddr = "127.0.0.1:8090"
+
+// Errors that aren't from the rules engine.
+var (
+	errNotFound      = errors.New("no game with that id")
+	errRoundNotOver  = errors.New("the round isn't over yet")
+	errSeriesOver    = errors.New("the series is over")
+	errRemotePlayers = errors.New("remote players can't take part in API games")
+)
+
+// Server holds the games being played through the API.
+type Server struct {
+	mu     sync.Mutex
+	games  map[string]*ui.Game // the current round of each game
+	nextID int
+}
+
+// NewServer returns a Server with no games.
+func NewServer() *Server {
+	return &Server{games: make(map[string]*ui.Game), nextID: 1}
+}
+
+// Handler routes the API's requests.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/games", s.createGame)
+	mux.HandleFunc("GET /api/games", s.listGames)
+	mux.HandleFunc("GET /api/games/{id}", s.withGame(s.getGame))
+	mux.HandleFunc("DELETE /api/games/{id}", s.deleteGame)
+	mux.HandleFunc("GET /api/games/{id}/moves", s.withGame(s.getMoves))
+	mux.HandleFunc("POST /api/games/{id}/moves", s.withGame(s.playMove))
+	mux.HandleFunc("POST /api/games/{id}/ai-move", s.withGame(s.aiMove))
+	mux.HandleFunc("POST /api/games/{id}/next-round", s.withGame(s.nextRound))
+	mux.HandleFunc("GET /api/leaderboard", getLeaderboard)
+	return mux
+}
+
+// gameReply describes a game.
+type gameReply struct {
+	ID     string           `json:"id"`
+	Config types.GameConfig `json:"config"`
+	State  types.GameState  `json:"state"`
+}
+
+// moveReply describes a move that was played and where it left the game.
+type moveReply struct {
+	Move      types.Move      `json:"move"`
+	Row       int             `json:"row"`
+	Won       bool            `json:"won,omitempty"`
+	Draw      bool            `json:"draw,omitempty"`
+	Solitaire int             `json:"solitaire,omitempty"` // counters removed by the solitaire rule
+	Overflow  bool            `json:"overflow,omitempty"`
+	State     types.GameState `json:"state"`
+}
+
+func (s *Server) createGame(w http.ResponseWriter, r *http.Request) {
+	var config types.GameConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err)
+		return
+	}
+	if err := config.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_config", err)
+		return
+	}
+	for _, playerType := range config.PlayerTypes[:config.PlayerCount] {
+		if playerType == types.RemotePlayer {
+			writeError(w, http.StatusBadRequest, "invalid_config", errRemotePlayers)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := strconv.Itoa(s.nextID)
+	s.nextID++
+	gw := ui.NewGameFromConfig(config)
+	s.games[id] = gw
+	writeJSON(w, http.StatusCreated, gameReply{ID: id, Config: gw.Config(), State: gw.State()})
+}
+
+func (s *Server) listGames(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	games := make([]gameReply, 0, len(s.games))
+	for id, gw := range s.games {
+		games = append(games, gameReply{ID: id, Config: gw.Config(), State: gw.State()})
+	}
+	sort.Slice(games, func(i, j int) bool {
+		a, _ := strconv.Atoi(games[i].ID)
+		b, _ := strconv.Atoi(games[j].ID)
+		return a < b
+	})
+	writeJSON(w, http.StatusOK, games)
+}
+
+func (s *Server) deleteGame(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gw, ok := s.games[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", errNotFound)
+		return
+	}
+	gw.CloseEngines()
+	delete(s.games, r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withGame looks up the game named in the path and holds the lock while
+// handle runs. handle returns the round to keep, which changes when a new
+// round starts.
+func (s *Server) withGame(handle func(w http.ResponseWriter, r *http.Request, id string, gw *ui.Game) *ui.Game) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		id := r.PathValue("id")
+		gw, ok := s.games[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "not_found", errNotFound)
+			return
+		}
+		s.games[id] = handle(w, r, id, gw)
+	}
+}
+
+func (s *Server) getGame(w http.ResponseWriter, r *http.Request, id string, gw *ui.Game) *ui.Game {
+	writeJSON(w, http.StatusOK, gameReply{ID: id, Config: gw.Config(), State: gw.State()})
+	return gw
+}
+
+func (s *Server) getMoves(w http.ResponseWriter, r *http.Request, id string, gw *ui.Game) *ui.Game {
+	moves := gw.History
+	if moves == nil {
+		moves = []types.Move{}
+	}
+	writeJSON(w, http.StatusOK, map[string][]types.Move{"moves": moves})
+	return gw
+}
+
+func (s *Server) playMove(w http.ResponseWriter, r *http.Request, id string, gw *ui.Game) *ui.Game {
+	var move struct {
+		Column *int `json:"column"`
+		Bomb   bool `json:"bomb"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err)
+		return gw
+	}
+	if move.Column == nil {
+		writeError(w, http.StatusBadRequest, "bad_request", errors.New("column is missing"))
+		return gw
+	}
+	play(w, gw, types.Move{Player: gw.CurrentTurn, Column: *move.Column, Bomb: move.Bomb})
+	return gw
+}
+
+// aiMove plays the AI's choice for the current player, at ?level= if given,
+// otherwise at the player's own level, or the medium AI's for a person.
+func (s *Server) aiMove(w http.ResponseWriter, r *http.Request, id string, gw *ui.Game) *ui.Game {
+	if gw.RoundOver {
+		writeMoveError(w, ui.ErrRoundOver)
+		return gw
+	}
+	level := gw.PlayerTypes[gw.CurrentTurn]
+	if name := r.URL.Query().Get("level"); name != "" {
+		var err error
+		if level, err = types.PlayerType(name); err != nil || !types.IsAI(level) {
+			writeError(w, http.StatusBadRequest, "bad_request", fmt.Errorf("%q is not an AI level", name))
+			return gw
+		}
+	} else if !types.IsAI(level) {
+		level = types.MediumAI
+	}
+	play(w, gw, gw.AIMove(level))
+	return gw
+}
+
+func (s *Server) nextRound(w http.ResponseWriter, r *http.Request, id string, gw *ui.Game) *ui.Game {
+	switch {
+	case gw.SeriesOver():
+		writeError(w, http.StatusConflict, "series_over", errSeriesOver)
+		return gw
+	case !gw.RoundOver:
+		writeError(w, http.StatusConflict, "round_not_over", errRoundNotOver)
+		return gw
+	}
+	next := gw.NextRound()
+	writeJSON(w, http.StatusOK, gameReply{ID: id, Config: next.Config(), State: next.State()})
+	return next
+}
+
+// play makes a move through the rules engine and replies with the result.
+func play(w http.ResponseWriter, gw *ui.Game, move types.Move) {
+	result, err := gw.PlayMove(move)
+	if err != nil {
+		writeMoveError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, moveReply{
+		Move:      types.Move{Player: result.Player, Column: result.Column, Bomb: move.Bomb},
+		Row:       result.Row,
+		Won:       result.Won,
+		Draw:      result.Draw,
+		Solitaire: result.Solitaire,
+		Overflow:  result.Overflow,
+		State:     gw.State(),
+	})
+}
+
+// writeMoveError turns a rules error from PlayMove into a reply.
+func writeMoveError(w http.ResponseWriter, err error) {
+	switch err {
+	case ui.ErrInvalidColumn:
+		writeError(w, http.StatusBadRequest, "invalid_column", err)
+	case ui.ErrColumnFull:
+		writeError(w, http.StatusConflict, "column_full", err)
+	case ui.ErrBombUsed:
+		writeError(w, http.StatusConflict, "bomb_used", err)
+	case ui.ErrBombDisabled:
+		writeError(w, http.StatusConflict, "bomb_disabled", err)
+	case ui.ErrRoundOver:
+		writeError(w, http.StatusConflict, "round_over", err)
+	default:
+		writeError(w, http.StatusInternalServerError, "internal", err)
+	}
+}
+
+// getLeaderboard serves the leaderboard file, with each row keyed by the
+// header's column names.
+func getLeaderboard(w http.ResponseWriter, r *http.Request) {
+	records, _ := ui.ReadLeaderboard()
+	players := []map[string]string{}
+	if len(records) > 1 {
+		header := records[0]
+		for _, record := range records[1:] {
+			player := make(map[string]string)
+			for i, value := range record {
+				if i < len(header) {
+					player[header[i]] = value
+				}
+			}
+			players = append(players, player)
+		}
+	}
+	writeJSON(w, http.StatusOK, players)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error(), "code": code})
+}
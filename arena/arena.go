@@ -0,0 +1,134 @@
+// Package arena plays every AI level against every other on a set of board
+// presets and rates them, so the levels have a measurable strength.
+package arena
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"insighthub.uk/connectron/v2/sim"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Errors returned by Run for options that can't make a calibration.
+var (
+	ErrTooFewPlayers = errors.New("the arena needs at least two AI levels")
+	ErrNoPresets     = errors.New("the arena needs at least one preset")
+)
+
+// Preset is a board and set of rules the levels are rated on.
+type Preset struct {
+	Name   string           `json:"name"`
+	Config types.GameConfig `json:"config"`
+}
+
+// Presets are the boards rated by default: the classic game, a big board
+// with longer lines, and the classic board with every special rule on.
+var Presets = []Preset{
+	{"classic", types.GameConfig{GridWidth: 7, GridHeight: 6, LineLength: 4}},
+	{"large", types.GameConfig{GridWidth: 12, GridHeight: 10, LineLength: 5}},
+	{"special", types.GameConfig{GridWidth: 7, GridHeight: 6, LineLength: 4, CornerBonus: true, SolitaireRule: true, BombCounter: true, OverflowRule: true}},
+}
+
+// FindPreset looks up one of the Presets by name.
+func FindPreset(name string) (Preset, error) {
+	for _, preset := range Presets {
+		if preset.Name == name {
+			return preset, nil
+		}
+	}
+	return Preset{}, fmt.Errorf("unknown preset %q", name)
+}
+
+// Options say what to play. Every pair of levels plays Games single rounds
+// on each preset, half with each level moving first.
+type Options struct {
+	Players []int // AI levels to rate
+	Presets []Preset
+	Games   int
+	Workers int   // games played at once; 0 for one per CPU
+	Seed    int64 // the same seed plays the same games
+}
+
+// Run plays the games and rates the levels on each preset and overall.
+func Run(opts Options) (*Calibration, error) {
+	if len(opts.Players) < 2 {
+		return nil, ErrTooFewPlayers
+	}
+	if len(opts.Presets) == 0 {
+		return nil, ErrNoPresets
+	}
+	if opts.Games < 2 {
+		return nil, errors.New("each pair of levels needs at least two games")
+	}
+	seen := make(map[int]bool)
+	for _, player := range opts.Players {
+		if !types.IsAI(player) {
+			return nil, fmt.Errorf("%s is not an AI level", types.PlayerTypeName(player))
+		}
+		if seen[player] {
+			return nil, fmt.Errorf("%s is entered twice", types.PlayerTypeName(player))
+		}
+		seen[player] = true
+	}
+
+	calibration := &Calibration{
+		Version: Version,
+		Created: time.Now().UTC().Truncate(time.Second),
+		Games:   opts.Games,
+		Seed:    opts.Seed,
+	}
+	var all []pairing
+	seed := opts.Seed
+	for _, preset := range opts.Presets {
+		var results []pairing
+		for i := range opts.Players {
+			for j := i + 1; j < len(opts.Players); j++ {
+				result := pairing{a: i, b: j}
+				// Half the games each way round, so moving first helps both alike
+				for _, seats := range [][2]int{{i, j}, {j, i}} {
+					config := preset.Config
+					config.PlayerCount = 2
+					config.PlayerTypes = []int{opts.Players[seats[0]], opts.Players[seats[1]]}
+					games := opts.Games / 2
+					if seats[0] == i {
+						games = opts.Games - games
+					}
+					stats, err := sim.Run(sim.Options{Config: config, Games: games, Workers: opts.Workers, Seed: seed})
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", preset.Name, err)
+					}
+					seed += int64(games)
+					result.add(seats[0] == i, stats)
+				}
+				results = append(results, result)
+			}
+		}
+		calibration.Presets = append(calibration.Presets, PresetRatings{
+			Preset:  preset,
+			Ratings: rate(opts.Players, results),
+		})
+		all = append(all, results...)
+	}
+	calibration.Overall = rate(opts.Players, all)
+	return calibration, nil
+}
+
+// pairing is how two levels, by index into the players, did against each
+// other. Unfinished games count as draws.
+type pairing struct {
+	a, b         int
+	aWins, bWins int
+	draws        int
+}
+
+func (p *pairing) add(aFirst bool, stats sim.Stats) {
+	first, second := stats.Wins[0], stats.Wins[1]
+	if !aFirst {
+		first, second = second, first
+	}
+	p.aWins += first
+	p.bWins += second
+	p.draws += stats.Draws + stats.Unfinished
+}
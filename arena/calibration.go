@@ -0,0 +1,77 @@
+package arena
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Version is written into every calibration file. Bump it whenever the
+// format changes in a way older builds can't read.
+const Version = 1
+
+// CalibrationPath is where the arena writes its ratings for the app to show.
+var CalibrationPath = filepath.Join("files", "calibration.json")
+
+// ErrNotCalibration is returned when reading a file that isn't one.
+var ErrNotCalibration = errors.New("not a Connectron calibration file")
+
+// PresetRatings are the ratings from the games on one preset.
+type PresetRatings struct {
+	Preset  Preset   `json:"preset"`
+	Ratings []Rating `json:"ratings"`
+}
+
+// Calibration is the outcome of an arena run.
+type Calibration struct {
+	Version int             `json:"version"`
+	Created time.Time       `json:"created"`
+	Games   int             `json:"games"` // per pair of levels on each preset
+	Seed    int64           `json:"seed"`
+	Presets []PresetRatings `json:"presets"`
+	Overall []Rating        `json:"overall"` // from the games on every preset together
+}
+
+// Rating looks up a level's overall rating.
+func (c *Calibration) Rating(playerType int) (Rating, bool) {
+	if c == nil {
+		return Rating{}, false
+	}
+	for _, rating := range c.Overall {
+		if rating.PlayerType == playerType {
+			return rating, true
+		}
+	}
+	return Rating{}, false
+}
+
+// ReadCalibration reads a calibration file.
+func ReadCalibration(filePath string) (*Calibration, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var c Calibration
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotCalibration, err)
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("%w: unknown version %d", ErrNotCalibration, c.Version)
+	}
+	return &c, nil
+}
+
+// WriteCalibration saves a calibration, replacing any file already there.
+func WriteCalibration(filePath string, c *Calibration) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
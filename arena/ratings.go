@@ -0,0 +1,91 @@
+package arena
+
+import (
+	"math"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Ratings are on the familiar Elo scale, centred so the levels average
+// meanRating. A 200 point gap means the stronger level scores about 76%.
+const (
+	meanRating = 1500
+	iterations = 1000
+)
+
+// Rating is how strong a level played.
+type Rating struct {
+	PlayerType int     `json:"playerType"`
+	Name       string  `json:"name"`
+	Rating     float64 `json:"rating"`
+	Games      int     `json:"games"`
+	Score      float64 `json:"score"` // share of the points won, a draw being half a point
+}
+
+// rate fits a Bradley-Terry model to the results, which unlike updating Elo
+// game by game doesn't depend on the order the games were played in. Every
+// pair is also given one pretend draw, so a level that won every game still
+// gets a finite rating.
+func rate(players []int, results []pairing) []Rating {
+	n := len(players)
+	games := make([][]float64, n)
+	for i := range games {
+		games[i] = make([]float64, n)
+	}
+	points := make([]float64, n)
+	ratings := make([]Rating, n)
+	for i, player := range players {
+		ratings[i] = Rating{PlayerType: player, Name: types.PlayerTypeName(player)}
+	}
+
+	for _, p := range results {
+		played := p.aWins + p.bWins + p.draws
+		games[p.a][p.b] += float64(played)
+		games[p.b][p.a] += float64(played)
+		points[p.a] += float64(p.aWins) + float64(p.draws)/2
+		points[p.b] += float64(p.bWins) + float64(p.draws)/2
+		ratings[p.a].Games += played
+		ratings[p.b].Games += played
+	}
+	for i := range ratings {
+		if ratings[i].Games > 0 {
+			ratings[i].Score = points[i] / float64(ratings[i].Games)
+		}
+	}
+	for i := range games {
+		for j := range games[i] {
+			if i != j {
+				games[i][j]++
+				points[i] += 0.5
+			}
+		}
+	}
+
+	// Minorisation-maximisation: each strength in turn becomes the points
+	// it won over the points it would be expected to win per unit strength
+	strength := make([]float64, n)
+	for i := range strength {
+		strength[i] = 1
+	}
+	for iter := 0; iter < iterations; iter++ {
+		for i := range strength {
+			expected := 0.0
+			for j := range strength {
+				if i != j {
+					expected += games[i][j] / (strength[i] + strength[j])
+				}
+			}
+			strength[i] = points[i] / expected
+		}
+	}
+
+	total := 0.0
+	for i := range ratings {
+		ratings[i].Rating = 400 * math.Log10(strength[i])
+		total += ratings[i].Rating
+	}
+	for i := range ratings {
+		ratings[i].Rating = math.Round(ratings[i].Rating - total/float64(n) + meanRating)
+	}
+	return ratings
+}
@@ -0,0 +1,139 @@
+// Package cli holds the command-line flags shared by Connectron's commands.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"insighthub.uk/connectron/v2/engine"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// GameFlags are the setup screen's options as command-line flags.
+type GameFlags struct {
+	width, height, lineLength, players, bestOf *int
+	hintBudget                                 *int
+	playerTypes, alliances, personalities      *string
+	aiForMissing, cornerBonus, solitaire, bomb *bool
+	overflow, enableAlliances                  *bool
+	engine                                     *string
+	moveTime                                   *time.Duration
+}
+
+// AddGameFlags defines the game setting flags on fs. defaultPlayer is the
+// -types default.
+func AddGameFlags(fs *flag.FlagSet, defaultPlayer string) *GameFlags {
+	return &GameFlags{
+		width:           fs.Int("width", 7, "grid width"),
+		height:          fs.Int("height", 6, "grid height"),
+		lineLength:      fs.Int("line", 4, "line length to win"),
+		players:         fs.Int("players", 2, "number of players"),
+		bestOf:          fs.Int("bestof", 1, "number of rounds in the series"),
+		hintBudget:      fs.Int("hints", 3, "hints each person may take a game"),
+		playerTypes:     fs.String("types", defaultPlayer, "comma separated player types: easy, medium, hard, neural, adaptive, engine, person, remote (the last one is repeated for the remaining seats)"),
+		alliances:       fs.String("alliances", "", "alliances as player numbers, e.g. 1,2;3,4"),
+		personalities:   fs.String("styles", "", "comma separated AI play styles by seat: "+strings.Join(types.Personalities, ", ")+" (blank for balanced)"),
+		aiForMissing:    fs.Bool("ai-for-missing", false, "let AI play for missing players"),
+		cornerBonus:     fs.Bool("corner", false, "enable corner bonus"),
+		solitaire:       fs.Bool("solitaire", false, "enable solitaire destruction"),
+		bomb:            fs.Bool("bomb", false, "enable bomb counter"),
+		overflow:        fs.Bool("overflow", false, "enable overflow rule"),
+		enableAlliances: fs.Bool("enable-alliances", false, "enable alliances rule"),
+		engine:          fs.String("engine", "", "command that runs the external engine, instead of the one in the settings"),
+		moveTime:        fs.Duration("movetime", 0, "time the external engine gets per move, instead of the one in the settings"),
+	}
+}
+
+// Config turns the parsed flags into game settings, checked against the
+// same limits as the setup screen.
+func (f *GameFlags) Config() (types.GameConfig, error) {
+	playerTypes, err := parsePlayerTypes(*f.playerTypes, *f.players)
+	if err != nil {
+		return types.GameConfig{}, err
+	}
+	alliances, err := parseAlliances(*f.alliances)
+	if err != nil {
+		return types.GameConfig{}, err
+	}
+	var personalities []string
+	if *f.personalities != "" {
+		for _, name := range strings.Split(*f.personalities, ",") {
+			personalities = append(personalities, strings.ToLower(strings.TrimSpace(name)))
+		}
+	}
+	config := types.GameConfig{
+		GridWidth:       *f.width,
+		GridHeight:      *f.height,
+		LineLength:      *f.lineLength,
+		PlayerCount:     *f.players,
+		BestOf:          *f.bestOf,
+		PlayerTypes:     playerTypes,
+		AIForMissing:    *f.aiForMissing,
+		CornerBonus:     *f.cornerBonus,
+		SolitaireRule:   *f.solitaire,
+		BombCounter:     *f.bomb,
+		OverflowRule:    *f.overflow,
+		EnableAlliances: *f.enableAlliances,
+		Alliances:       alliances,
+		Personalities:   personalities,
+		HintBudget:      *f.hintBudget,
+	}
+	if err := config.Validate(); err != nil {
+		return types.GameConfig{}, err
+	}
+	return config, nil
+}
+
+// Engine returns the saved external engine settings with any changes made
+// by the -engine and -movetime flags.
+func (f *GameFlags) Engine() engine.Settings {
+	settings := engine.LoadSettings()
+	if command := strings.Fields(*f.engine); len(command) > 0 {
+		settings.Command, settings.Args = command[0], command[1:]
+	}
+	if *f.moveTime > 0 {
+		settings.MoveTime = *f.moveTime
+	}
+	return settings
+}
+
+func parsePlayerTypes(list string, players int) ([]int, error) {
+	names := strings.Split(list, ",")
+	if len(names) > players {
+		return nil, fmt.Errorf("%d player types given for %d players", len(names), players)
+	}
+	playerTypes := make([]int, 0, players)
+	for _, name := range names {
+		playerType, err := types.PlayerType(name)
+		if err != nil {
+			return nil, err
+		}
+		playerTypes = append(playerTypes, playerType)
+	}
+	for len(playerTypes) < players {
+		playerTypes = append(playerTypes, playerTypes[len(playerTypes)-1])
+	}
+	return playerTypes, nil
+}
+
+// parseAlliances reads "1,2;3,4" into the same form as the alliance manager window
+func parseAlliances(list string) ([][]string, error) {
+	var alliances [][]string
+	if list == "" {
+		return alliances, nil
+	}
+	for _, group := range strings.Split(list, ";") {
+		var alliance []string
+		for _, player := range strings.Split(group, ",") {
+			var number int
+			if _, err := fmt.Sscanf(strings.TrimSpace(player), "%d", &number); err != nil {
+				return nil, fmt.Errorf("bad player number %q in alliances", player)
+			}
+			alliance = append(alliance, fmt.Sprintf("Player-%d", number))
+		}
+		alliances = append(alliances, alliance)
+	}
+	return alliances, nil
+}
@@ -0,0 +1,30 @@
+// Command connectron-tui plays Connectron in a terminal. Unlike the desktop
+// app it needs no graphics libraries, so it can be built and run over SSH on
+// machines without a display. It is the same as running "connectron tui".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"insighthub.uk/connectron/v2/cli"
+	"insighthub.uk/connectron/v2/tui"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+func main() {
+	gameOptions := cli.AddGameFlags(flag.CommandLine, "person,medium")
+	flag.Parse()
+
+	config, err := gameOptions.Config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	ui.EngineSettings = gameOptions.Engine()
+	if err := tui.Run(config, os.Stdin, os.Stdout); err != nil && err != tui.ErrQuit {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,633 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"insighthub.uk/connectron/v2/api"
+	"insighthub.uk/connectron/v2/arena"
+	"insighthub.uk/connectron/v2/cli"
+	"insighthub.uk/connectron/v2/learn"
+	"insighthub.uk/connectron/v2/network"
+	"insighthub.uk/connectron/v2/nn"
+	"insighthub.uk/connectron/v2/puzzle"
+	"insighthub.uk/connectron/v2/saves"
+	"insighthub.uk/connectron/v2/sim"
+	"insighthub.uk/connectron/v2/tui"
+	"insighthub.uk/connectron/v2/tune"
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+	"insighthub.uk/connectron/v2/web"
+)
+
+// runCommand runs a command-line subcommand such as "web". Flags with no
+// subcommand start a game straight away, as "play" does. It returns false
+// when there is nothing to run and the desktop app should start as normal.
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if strings.HasPrefix(args[0], "-") {
+		runPlay(args)
+		return true
+	}
+	switch args[0] {
+	case "play":
+		runPlay(args[1:])
+	case "open":
+		runOpen(args[1:])
+	case "simulate":
+		runSimulation(args[1:])
+	case "arena":
+		runArena(args[1:])
+	case "tune":
+		runTuning(args[1:])
+	case "train":
+		runTraining(args[1:])
+	case "analyse":
+		runAnalysis(args[1:])
+	case "puzzle":
+		runPuzzleCheck(args[1:])
+	case "api":
+		runAPIServer(args[1:])
+	case "web":
+		runWebServer(args[1:])
+	case "lobby":
+		runLobbyServer(args[1:])
+	case "tui":
+		runTerminalGame(args[1:])
+	default:
+		return false
+	}
+	return true
+}
+
+// runSimulation plays AI-only games in bulk and prints their statistics
+func runSimulation(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	games := fs.Int("games", 100, "number of games (single rounds) to play")
+	workers := fs.Int("workers", runtime.NumCPU(), "games to play at once")
+	seed := fs.Int64("seed", 1, "random seed; the same seed plays the same games")
+	format := fs.String("format", "csv", "output format: csv or json")
+	out := fs.String("out", "", "file to write the statistics to instead of standard output")
+	gameOptions := cli.AddGameFlags(fs, "medium")
+	fs.Parse(args)
+
+	config, err := gameOptions.Config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	ui.EngineSettings = gameOptions.Engine()
+	write := sim.WriteCSV
+	switch *format {
+	case "csv":
+	case "json":
+		write = sim.WriteJSON
+	default:
+		fmt.Fprintln(os.Stderr, "unknown format", *format)
+		os.Exit(2)
+	}
+
+	stats, err := sim.Run(sim.Options{Config: config, Games: *games, Workers: *workers, Seed: *seed})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	output := os.Stdout
+	if *out != "" {
+		if output, err = os.Create(*out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer output.Close()
+	}
+	if err := write(output, stats); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runArena plays the AI levels against each other and writes their ratings
+// where the app will show them
+func runArena(args []string) {
+	fs := flag.NewFlagSet("arena", flag.ExitOnError)
+	games := fs.Int("games", 100, "games for each pair of levels on each preset")
+	players := fs.String("players", "easy,medium,hard", "comma-separated AI levels to rate, such as neural or engine (which uses the saved engine settings)")
+	presets := fs.String("presets", "classic,large,special", "comma-separated board presets to play on")
+	workers := fs.Int("workers", runtime.NumCPU(), "games to play at once")
+	seed := fs.Int64("seed", 1, "random seed; the same seed plays the same games")
+	out := fs.String("out", arena.CalibrationPath, "file to write the calibration to")
+	fs.Parse(args)
+
+	opts := arena.Options{Games: *games, Workers: *workers, Seed: *seed}
+	for _, name := range strings.Split(*players, ",") {
+		playerType, err := types.PlayerType(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		opts.Players = append(opts.Players, playerType)
+	}
+	for _, name := range strings.Split(*presets, ",") {
+		preset, err := arena.FindPreset(strings.TrimSpace(name))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		opts.Presets = append(opts.Presets, preset)
+	}
+
+	calibration, err := arena.Run(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, preset := range calibration.Presets {
+		printRatings(preset.Preset.Name, preset.Ratings)
+	}
+	printRatings("overall", calibration.Overall)
+	if err := arena.WriteCalibration(*out, calibration); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("Calibration written to", *out)
+}
+
+// printRatings prints one table of ratings, strongest first
+func printRatings(title string, ratings []arena.Rating) {
+	ratings = append([]arena.Rating(nil), ratings...)
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i].Rating > ratings[j].Rating })
+	fmt.Println(title)
+	for _, rating := range ratings {
+		fmt.Printf("  %-8s %5.0f  %5.1f%% of %d games\n", rating.Name, rating.Rating, 100*rating.Score, rating.Games)
+	}
+}
+
+// runAnalysis goes over a saved game with the AI and prints, or saves, the
+// report of every move it would have played differently
+func runAnalysis(args []string) {
+	fs := flag.NewFlagSet("analyse", flag.ExitOnError)
+	out := fs.String("out", "", "file to write the report to instead of standard output")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: connectron analyse [flags] FILE")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	record, err := saves.ReadGame(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	analysis, err := ui.Analyse(record)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "The saved game doesn't follow the rules:", err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		fmt.Print(analysis.Report())
+		return
+	}
+	if err := os.WriteFile(*out, []byte(analysis.Report()), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("Report written to", *out)
+}
+
+// runPuzzleCheck checks a puzzle file can be solved, listing the moves
+// that start a winning line.
+func runPuzzleCheck(args []string) {
+	fs := flag.NewFlagSet("puzzle", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: connectron puzzle FILE")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	p, err := puzzle.Read(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	attempt, err := ui.StartPuzzle(p)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, p.Title+":", err)
+		os.Exit(1)
+	}
+	solutions, err := attempt.Solutions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, p.Title+":", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: Player %d to win in %d\n", p.Title, p.ToMove, p.Moves)
+	for _, move := range solutions {
+		if move.Bomb {
+			fmt.Printf("  bomb counter in column %d\n", move.Column+1)
+		} else {
+			fmt.Printf("  column %d\n", move.Column+1)
+		}
+	}
+}
+
+// runTuning tunes the hard AI's weights by self-play for one kind of game
+// and saves them as a profile the hard AI picks up for games like it
+func runTuning(args []string) {
+	fs := flag.NewFlagSet("tune", flag.ExitOnError)
+	iterations := fs.Int("iterations", 50, "tuning steps to take")
+	games := fs.Int("games", 16, "games played at each step")
+	validate := fs.Int("validate", 100, "games between the tuned and starting weights at the end")
+	workers := fs.Int("workers", runtime.NumCPU(), "games to play at once")
+	seed := fs.Int64("seed", 1, "random seed; the same seed tunes the same weights")
+	out := fs.String("out", ui.WeightsPath, "weights file to save the profile in")
+	gameOptions := cli.AddGameFlags(fs, "hard")
+	fs.Parse(args)
+
+	config, err := gameOptions.Config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	// Carry on from the closest profile tuned so far
+	start := ui.DefaultWeights
+	profiles, _ := ui.ReadWeightProfiles(*out)
+	if profile, ok := ui.BestProfile(profiles, config); ok {
+		start = profile.Weights
+	}
+
+	result, err := tune.Run(tune.Options{
+		Config:     config,
+		Start:      start,
+		Iterations: *iterations,
+		Games:      *games,
+		Validation: *validate,
+		Workers:    *workers,
+		Seed:       *seed,
+		Progress: func(iteration int, w ui.Weights) {
+			fmt.Printf("step %d: %+v\n", iteration, w)
+		},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	fmt.Printf("Tuned weights scored %.1f%% against the starting weights\n", 100*result.Score)
+	if result.Score < 0.5 {
+		fmt.Println("They did no better, so nothing was saved")
+		return
+	}
+
+	profile := ui.ProfileFor(config, result.Weights)
+	profile.Score = result.Score
+	profile.Tuned = time.Now().UTC().Truncate(time.Second)
+	if err := ui.SaveWeightProfile(*out, profile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("Profile saved to", *out)
+}
+
+// runTraining trains the neural AI's network by self-play and saves it
+// where the neural AI will find it
+func runTraining(args []string) {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	generations := fs.Int("generations", 5, "rounds of playing games and training on them")
+	games := fs.Int("games", 100, "self-play games each generation")
+	hidden := fs.Int("hidden", 16, "units in the network's hidden layer")
+	epochs := fs.Int("epochs", 5, "passes over the positions after each generation")
+	rate := fs.Float64("rate", 0.01, "learning rate")
+	explore := fs.Float64("explore", 0.1, "chance of a random move, so games don't all play out the same")
+	workers := fs.Int("workers", runtime.NumCPU(), "games to play at once")
+	seed := fs.Int64("seed", 1, "random seed; the same seed trains the same network")
+	out := fs.String("out", ui.ModelPath, "file to write the model to")
+	gameOptions := cli.AddGameFlags(fs, "neural")
+	fs.Parse(args)
+
+	config, err := gameOptions.Config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	model, err := learn.Train(learn.Options{
+		Config:      config,
+		Generations: *generations,
+		Games:       *games,
+		Hidden:      *hidden,
+		Epochs:      *epochs,
+		Rate:        *rate,
+		Explore:     *explore,
+		Workers:     *workers,
+		Seed:        *seed,
+		Progress: func(generation, positions int, loss float64) {
+			fmt.Printf("generation %d: %d positions, loss %.4f\n", generation, positions, loss)
+		},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := nn.Write(*out, model); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("Model written to", *out)
+}
+
+// runAPIServer serves the HTTP API for scripts to play games through
+func runAPIServer(args []string) {
+	fs := flag.NewFlagSet("api", flag.ExitOnError)
+	addr := fs.String("addr", api.DefaultAddr, "address to serve the API on")
+	fs.Parse(args)
+
+	fmt.Println("Serving the Connectron API on", *addr)
+	if err := http.ListenAndServe(*addr, api.NewServer().Handler()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runWebServer hosts a game for browsers on the LAN
+func runWebServer(args []string) {
+	fs := flag.NewFlagSet("web", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to serve the browser client on")
+	spectatorDelay := fs.Duration("spectator-delay", network.DefaultSpectatorDelay, "how far behind the live game spectators are")
+	grace := fs.Duration("grace", network.DefaultGracePeriod, "how long dropped players have to reconnect")
+	gameOptions := cli.AddGameFlags(fs, "remote")
+	fs.Parse(args)
+
+	config, err := gameOptions.Config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	ui.EngineSettings = gameOptions.Engine()
+
+	server := network.NewServer(ui.NewGameFromConfig(config))
+	server.SetSpectatorDelay(*spectatorDelay)
+	server.SetGracePeriod(*grace)
+	fmt.Println("Serving Connectron on", *addr)
+	if err := http.ListenAndServe(*addr, web.NewHandler(server)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runLobbyServer runs a lobby where players create rooms and start games
+func runLobbyServer(args []string) {
+	fs := flag.NewFlagSet("lobby", flag.ExitOnError)
+	addr := fs.String("addr", fmt.Sprintf(":%d", network.DefaultLobbyPort), "address to listen on")
+	spectatorDelay := fs.Duration("spectator-delay", network.DefaultSpectatorDelay, "how far behind the live game spectators are")
+	grace := fs.Duration("grace", network.DefaultGracePeriod, "how long dropped players have to reconnect")
+	fs.Parse(args)
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("Connectron lobby listening on", listener.Addr())
+	lobby := network.NewLobby()
+	lobby.SetSpectatorDelay(*spectatorDelay)
+	lobby.SetGracePeriod(*grace)
+	if err := lobby.Serve(listener); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runTerminalGame plays a game in the terminal instead of a window
+func runTerminalGame(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	gameOptions := cli.AddGameFlags(fs, "person,medium")
+	fs.Parse(args)
+
+	config, err := gameOptions.Config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	ui.EngineSettings = gameOptions.Engine()
+	if err := tui.Run(config, os.Stdin, os.Stdout); err != nil && err != tui.ErrQuit {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Ways a game started from the command line can be shown
+const (
+	modeGUI      = "gui"
+	modeTUI      = "tui"
+	modeHeadless = "headless"
+)
+
+// runPlay starts a game set up entirely by flags
+func runPlay(args []string) {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	mode := fs.String("mode", modeGUI, "how to show the game: gui, tui or headless")
+	port := fs.Int("port", network.DefaultPort, "port to host on when any player is remote")
+	gameOptions := cli.AddGameFlags(fs, "person,medium")
+	fs.Parse(args)
+
+	config, err := gameOptions.Config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	ui.EngineSettings = gameOptions.Engine()
+	startGame(ui.NewGameFromConfig(config), *mode, *port)
+}
+
+// runOpen carries on a saved game, or replays it if the series is over
+func runOpen(args []string) {
+	fs := flag.NewFlagSet("open", flag.ExitOnError)
+	mode := fs.String("mode", modeGUI, "how to show the game: gui, tui or headless")
+	port := fs.Int("port", network.DefaultPort, "port to host on when any player is remote")
+	replay := fs.Bool("replay", false, "replay the game instead of carrying it on")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: connectron open [flags] FILE")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	record, err := saves.ReadGame(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	gw, err := ui.LoadGame(record)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "The saved game doesn't follow the rules:", err)
+		os.Exit(1)
+	}
+	if !*replay && !gw.SeriesOver() {
+		startGame(gw, *mode, *port)
+		return
+	}
+	switch *mode {
+	case modeGUI:
+		runWindow(func(a fyne.App) { ui.ReplayWindow(record, a) })
+	case modeTUI, modeHeadless:
+		for i, winner := range gw.Winners {
+			printRound(i+1, winner)
+		}
+		printSeries(gw.Winners)
+	default:
+		fmt.Fprintln(os.Stderr, "unknown mode", *mode)
+		os.Exit(2)
+	}
+}
+
+// startGame shows a game the way chosen with -mode
+func startGame(gw *ui.Game, mode string, port int) {
+	switch mode {
+	case modeGUI:
+		runWindow(func(a fyne.App) {
+			if hasRemotePlayers(gw) {
+				hostGame(gw, strconv.Itoa(port), strconv.Itoa(int(network.DefaultSpectatorDelay.Seconds())), strconv.Itoa(int(network.DefaultGracePeriod.Seconds())))
+				return
+			}
+			if gw.RoundOver && !gw.SeriesOver() {
+				gw = gw.NextRound() // saved just as a round finished
+			}
+			ui.MainGameWindow(gw, a)
+		})
+	case modeTUI:
+		if err := tui.Play(gw, os.Stdin, os.Stdout); err != nil && err != tui.ErrQuit {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case modeHeadless:
+		if err := runHeadless(gw, port); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, "unknown mode", mode)
+		os.Exit(2)
+	}
+}
+
+func hasRemotePlayers(gw *ui.Game) bool {
+	for _, playerType := range gw.PlayerTypes[:gw.Players] {
+		if playerType == types.RemotePlayer {
+			return true
+		}
+	}
+	return false
+}
+
+// runHeadless plays a game without showing the board. AI seats play each
+// other; if any seat is remote the game is hosted for them to join.
+func runHeadless(gw *ui.Game, port int) error {
+	for i, playerType := range gw.PlayerTypes[:gw.Players] {
+		if playerType == types.HumanPlayer {
+			return fmt.Errorf("player %d is a person at this computer, which needs -mode gui or tui", i+1)
+		}
+	}
+	defer gw.CloseEngines()
+	if hasRemotePlayers(gw) {
+		return hostHeadless(gw, port)
+	}
+
+	reported := len(gw.Winners)
+	for {
+		if gw.RoundOver {
+			for ; reported < len(gw.Winners); reported++ {
+				printRound(reported+1, gw.Winners[reported])
+			}
+			if gw.SeriesOver() {
+				break
+			}
+			gw = gw.NextRound()
+		}
+		if _, err := gw.PlayMove(gw.AIMove(gw.PlayerTypes[gw.CurrentTurn])); err != nil {
+			return err
+		}
+	}
+	printSeries(gw.Winners)
+	return nil
+}
+
+// hostHeadless hosts a game on port and reports each round until the
+// series is over.
+func hostHeadless(gw *ui.Game, port int) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	server := network.NewServer(gw)
+	defer server.Close()
+	go server.Serve(listener)
+	fmt.Println("Hosting Connectron on", listener.Addr())
+
+	watcher, err := server.LocalClient("Host")
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	reported := 0
+	for state := range watcher.Updates() {
+		for ; reported < len(state.Winners); reported++ {
+			printRound(reported+1, state.Winners[reported])
+		}
+		if state.SeriesOver {
+			printSeries(state.Winners)
+			return nil
+		}
+	}
+	return fmt.Errorf("the game ended before the series was over")
+}
+
+func printRound(round, winner int) {
+	if winner == 0 {
+		fmt.Printf("Round %d: draw\n", round)
+		return
+	}
+	fmt.Printf("Round %d: player %d wins\n", round, winner)
+}
+
+// printSeries sums up a finished series
+func printSeries(winners []int) {
+	wins := make(map[int]int)
+	for _, winner := range winners {
+		if winner != 0 {
+			wins[winner]++
+		}
+	}
+	best, bestWins, tied := 0, 0, false
+	for player := 1; player <= types.MaxPlayers; player++ {
+		switch {
+		case wins[player] > bestWins:
+			best, bestWins, tied = player, wins[player], false
+		case wins[player] > 0 && wins[player] == bestWins:
+			tied = true
+		}
+	}
+	if best == 0 || tied {
+		fmt.Println("The series is a draw")
+		return
+	}
+	fmt.Printf("Player %d wins the series with %d of %d rounds\n", best, bestWins, len(winners))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/saves"
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// Where correspondence play keeps its files
+var (
+	correspondenceKeyPath  = filepath.Join("files", "correspondence.key")
+	correspondenceSeenPath = filepath.Join("files", "correspondence.csv")
+)
+
+// createCorrespondencePane builds the tab for play-by-file games. New games
+// use whatever is currently chosen on the Setup Game tab.
+func createCorrespondencePane(a fyne.App, parent fyne.Window, currentConfig func() types.GameConfig) fyne.CanvasObject {
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapWord
+
+	// send saves the game after our move, ready to pass to the next player
+	send := func(game *saves.Correspondence) {
+		dialog.ShowFileSave(func(file fyne.URIWriteCloser, err error) {
+			if err != nil || file == nil {
+				statusLabel.SetText("The move wasn't saved! Open the game again to redo it.")
+				return
+			}
+			file.Close()
+			if err := saves.WriteCorrespondence(file.URI().Path(), game); err != nil {
+				statusLabel.SetText("Could not save the move file: " + err.Error())
+				return
+			}
+			rememberCorrespondence(game)
+			statusLabel.SetText("Saved " + file.URI().Name() + ". Send it to the next player.")
+		}, parent)
+	}
+
+	open := func(game *saves.Correspondence) {
+		key, err := saves.LoadOrCreateKey(correspondenceKeyPath)
+		if err != nil {
+			statusLabel.SetText("Could not load your signing key: " + err.Error())
+			return
+		}
+		gw, err := ui.OpenCorrespondence(game)
+		if err != nil {
+			statusLabel.SetText("This move file can't be trusted: " + err.Error())
+			return
+		}
+		if !seenBefore(game) {
+			statusLabel.SetText("This move file is older than one you have already played. Ask for the latest one.")
+			return
+		}
+		statusLabel.SetText("")
+		ui.CorrespondenceWindow(game, gw, key, send, a)
+	}
+
+	newButton := widget.NewButton("New Correspondence Game from Setup", func() {
+		game, err := saves.NewCorrespondence(currentConfig())
+		if err != nil {
+			statusLabel.SetText("Could not start a correspondence game: " + err.Error())
+			return
+		}
+		open(game)
+	})
+	openButton := widget.NewButton("Open Move File", func() {
+		dialog.ShowFileOpen(func(file fyne.URIReadCloser, err error) {
+			if err != nil || file == nil {
+				return
+			}
+			file.Close()
+			game, err := saves.ReadCorrespondence(file.URI().Path())
+			if err != nil {
+				statusLabel.SetText("Could not open the move file: " + err.Error())
+				return
+			}
+			open(game)
+		}, parent)
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Play slowly by passing a move file between players. Every move is signed, and the whole history is checked against the rules when the file is opened."),
+		newButton,
+		openButton,
+		statusLabel,
+	)
+}
+
+// seenBefore checks a game carries on from the newest copy of it we have
+// played, so an older file can't be passed off as the latest.
+func seenBefore(game *saves.Correspondence) bool {
+	records, _ := saves.ReadCSV(correspondenceSeenPath)
+	for _, record := range records {
+		if len(record) < 3 || record[0] != game.ID {
+			continue
+		}
+		count, err := strconv.Atoi(record[1])
+		if err != nil {
+			return false
+		}
+		return game.Extends(count, record[2])
+	}
+	return true
+}
+
+// rememberCorrespondence records the newest copy of a game we have played.
+func rememberCorrespondence(game *saves.Correspondence) {
+	records, _ := saves.ReadCSV(correspondenceSeenPath)
+	record := []string{game.ID, strconv.Itoa(len(game.Moves)), game.Head()}
+	found := false
+	for i := range records {
+		if len(records[i]) > 0 && records[i][0] == game.ID {
+			records[i] = record
+			found = true
+		}
+	}
+	if !found {
+		records = append(records, record)
+	}
+	if err := saves.WriteCSV(correspondenceSeenPath, records); err != nil {
+		fmt.Println("Error saving correspondence history:", err)
+	}
+}
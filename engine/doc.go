@@ -0,0 +1,43 @@
+// Package engine runs AI programs written outside Connectron, so bots can be
+// written in any language and played against people or the built-in AI.
+//
+// An engine reads commands from its standard input and writes replies to its
+// standard output, one per line, with words separated by spaces. Seats,
+// columns and players count from 1. Lines the engine doesn't understand
+// should be ignored, and Connectron ignores any line from the engine starting
+// with "info", which is handy for debugging output.
+//
+// Commands sent to the engine:
+//
+//	cxp <version>
+//		Sent once when the engine starts. The engine may reply with
+//		"id name <name>" and must finish with "cxpok" within 5 seconds.
+//
+//	newgame width <w> height <h> line <n> players <p> seat <s> rules <list> alliances <list>
+//		A round is starting and the engine plays seat s. rules is a comma
+//		separated list of corner, solitaire, bomb and overflow, or "-" for
+//		none. alliances lists allied seats as "1,2;3,4", or "-" for none.
+//
+//	isready
+//		Always follows newgame. The engine replies "readyok" once it is set
+//		up, within 5 seconds.
+//
+//	position turn <s> bombsused <list> grid <rows> moves <move>...
+//		The board as it stands, with seat s to move. bombsused lists the
+//		seats that have used their bomb counter, or "-". grid has the rows
+//		from top to bottom separated by "/", with "." for an empty cell and
+//		the player number for a counter, 1-9 then "a" for player 10. moves
+//		lists the round's moves so far (possibly none); the grid already has
+//		the special rules applied, so engines needn't replay them.
+//
+//	go movetime <ms>
+//		The engine has ms milliseconds to reply "bestmove <column>", or
+//		"bestmove <column>b" to drop a bomb counter.
+//
+//	quit
+//		The engine should exit. It is stopped if it hasn't within a second.
+//
+// An engine that crashes, takes too long, or answers with a move that breaks
+// the rules is stopped for the rest of the game, and the medium AI plays its
+// seat instead.
+package engine
@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// ProtocolVersion is sent in the handshake so engines can tell if they are
+// out of date.
+const ProtocolVersion = 1
+
+// How long an engine has to answer. An engine is given its move time plus
+// moveGrace to reply with a move, to allow for starting up and pipes.
+const (
+	HandshakeTimeout = 5 * time.Second
+	DefaultMoveTime  = time.Second
+	moveGrace        = 500 * time.Millisecond
+	quitTimeout      = time.Second
+)
+
+// Ways an engine can let the game down. Once it has, it isn't asked again.
+var (
+	ErrNoEngine = errors.New("no external engine has been set up")
+	ErrTimeout  = errors.New("engine took too long to answer")
+	ErrCrashed  = errors.New("engine stopped running")
+	ErrBadReply = errors.New("engine sent a reply that doesn't follow the protocol")
+	ErrIllegal  = errors.New("engine chose a move that breaks the rules")
+	ErrClosed   = errors.New("engine has been closed")
+)
+
+// Engine is a running engine program.
+type Engine struct {
+	Name     string // from its "id name" line, or the command if it gave none
+	moveTime time.Duration
+
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string   // closed when the engine's output ends
+	done  chan struct{} // closed once the engine has stopped
+
+	mu      sync.Mutex
+	stopped error // why the engine was stopped; nil while it is running
+}
+
+// Start runs the engine program and waits for it to finish the handshake.
+func Start(settings Settings) (*Engine, error) {
+	if settings.Command == "" {
+		return nil, ErrNoEngine
+	}
+	cmd := exec.Command(settings.Command, settings.Args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		Name:     settings.Command,
+		moveTime: settings.MoveTime,
+		cmd:      cmd,
+		stdin:    stdin,
+		lines:    make(chan string, 64),
+		done:     make(chan struct{}),
+	}
+	if e.moveTime <= 0 {
+		e.moveTime = DefaultMoveTime
+	}
+	go e.read(stdout)
+
+	if err := e.send(fmt.Sprintf("cxp %d", ProtocolVersion)); err != nil {
+		return nil, e.fail(err)
+	}
+	err = e.await(HandshakeTimeout, func(words []string) (bool, error) {
+		if len(words) >= 3 && words[0] == "id" && words[1] == "name" {
+			e.Name = strings.Join(words[2:], " ")
+		}
+		return words[0] == "cxpok", nil
+	})
+	if err != nil {
+		return nil, e.fail(err)
+	}
+	return e, nil
+}
+
+// read passes the engine's output on a line at a time.
+func (e *Engine) read(stdout io.Reader) {
+	defer close(e.lines)
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		select {
+		case e.lines <- scanner.Text():
+		case <-e.done:
+			return
+		}
+	}
+}
+
+func (e *Engine) send(line string) error {
+	_, err := io.WriteString(e.stdin, line+"\n")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCrashed, err)
+	}
+	return nil
+}
+
+// await reads lines until handle says it has the one it wants, or the time
+// runs out. Blank lines are skipped, as are "info" lines, which engines may
+// send at any time.
+func (e *Engine) await(timeout time.Duration, handle func(words []string) (bool, error)) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				return ErrCrashed
+			}
+			words := strings.Fields(line)
+			if len(words) == 0 || words[0] == "info" {
+				continue
+			}
+			done, err := handle(words)
+			if err != nil || done {
+				return err
+			}
+		case <-timer.C:
+			return ErrTimeout
+		}
+	}
+}
+
+// NewGame tells the engine a round is starting and which seat it plays.
+// Seats count from 0 here, as everywhere in the code, and from 1 on the wire.
+func (e *Engine) NewGame(config types.GameConfig, seat int) error {
+	if err := e.Err(); err != nil {
+		return err
+	}
+	if err := e.send(newGameLine(config, seat)); err != nil {
+		return e.fail(err)
+	}
+	if err := e.send("isready"); err != nil {
+		return e.fail(err)
+	}
+	err := e.await(HandshakeTimeout, func(words []string) (bool, error) {
+		return words[0] == "readyok", nil
+	})
+	if err != nil {
+		return e.fail(err)
+	}
+	return nil
+}
+
+// BestMove sends the position and asks the engine for its move. The move
+// still has to be checked against the rules; call Fail if it breaks them.
+func (e *Engine) BestMove(state types.GameState, moves []types.Move) (types.Move, error) {
+	if err := e.Err(); err != nil {
+		return types.Move{}, err
+	}
+	if err := e.send(positionLine(state, moves)); err != nil {
+		return types.Move{}, e.fail(err)
+	}
+	if err := e.send("go movetime " + strconv.FormatInt(e.moveTime.Milliseconds(), 10)); err != nil {
+		return types.Move{}, e.fail(err)
+	}
+
+	var move types.Move
+	err := e.await(e.moveTime+moveGrace, func(words []string) (bool, error) {
+		if words[0] != "bestmove" {
+			return false, nil
+		}
+		if len(words) != 2 {
+			return false, ErrBadReply
+		}
+		var err error
+		move, err = parseMove(words[1], len(state.Grid[0]))
+		return true, err
+	})
+	if err != nil {
+		return types.Move{}, e.fail(err)
+	}
+	move.Player = state.CurrentTurn
+	return move, nil
+}
+
+// Fail stops the engine for good, for problems only the caller can spot
+// such as a move that breaks the rules. It returns err, with the engine's
+// name, so it can be passed straight on.
+func (e *Engine) Fail(err error) error {
+	return e.fail(err)
+}
+
+func (e *Engine) fail(err error) error {
+	if e.stop(err) {
+		e.cmd.Process.Kill()
+		go e.cmd.Wait()
+	}
+	return e.Err()
+}
+
+// stop marks the engine as stopped, reporting false if it already was.
+func (e *Engine) stop(err error) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped != nil {
+		return false
+	}
+	e.stopped = fmt.Errorf("%s: %w", e.Name, err)
+	close(e.done)
+	return true
+}
+
+// Err returns why the engine was stopped, or nil if it is still running.
+func (e *Engine) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stopped
+}
+
+// Close asks the engine to quit, and stops it if it doesn't in time.
+func (e *Engine) Close() error {
+	if !e.stop(ErrClosed) {
+		return nil
+	}
+	e.send("quit")
+	e.stdin.Close()
+	exited := make(chan error, 1)
+	go func() { exited <- e.cmd.Wait() }()
+	select {
+	case err := <-exited:
+		return err
+	case <-time.After(quitTimeout):
+		e.cmd.Process.Kill()
+		return ErrTimeout
+	}
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// newGameLine describes a round's settings and the engine's seat.
+func newGameLine(config types.GameConfig, seat int) string {
+	var rules []string
+	if config.CornerBonus {
+		rules = append(rules, "corner")
+	}
+	if config.SolitaireRule {
+		rules = append(rules, "solitaire")
+	}
+	if config.BombCounter {
+		rules = append(rules, "bomb")
+	}
+	if config.OverflowRule {
+		rules = append(rules, "overflow")
+	}
+
+	var alliances []string
+	if config.EnableAlliances {
+		for _, alliance := range config.Alliances {
+			seats := make([]string, len(alliance))
+			for i, player := range alliance {
+				seats[i] = strings.TrimPrefix(player, "Player-")
+			}
+			alliances = append(alliances, strings.Join(seats, ","))
+		}
+	}
+
+	return fmt.Sprintf("newgame width %d height %d line %d players %d seat %d rules %s alliances %s",
+		config.GridWidth, config.GridHeight, config.LineLength, config.PlayerCount, seat+1,
+		listOrDash(rules, ","), listOrDash(alliances, ";"))
+}
+
+// positionLine describes the board and whose turn it is.
+func positionLine(state types.GameState, moves []types.Move) string {
+	var bombs []string
+	for seat, used := range state.BombCounters {
+		if used {
+			bombs = append(bombs, strconv.Itoa(seat+1))
+		}
+	}
+
+	rows := make([]string, len(state.Grid))
+	for i, row := range state.Grid {
+		var b strings.Builder
+		for _, cell := range row {
+			if cell < 0 {
+				b.WriteByte('.')
+			} else {
+				b.WriteString(strconv.FormatInt(int64(cell+1), 36))
+			}
+		}
+		rows[i] = b.String()
+	}
+
+	line := fmt.Sprintf("position turn %d bombsused %s grid %s moves", state.CurrentTurn+1, listOrDash(bombs, ","), strings.Join(rows, "/"))
+	for _, move := range moves {
+		line += " " + formatMove(move)
+	}
+	return line
+}
+
+func listOrDash(items []string, sep string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, sep)
+}
+
+// formatMove writes a move as its column from 1, with "b" for a bomb.
+func formatMove(move types.Move) string {
+	text := strconv.Itoa(move.Column + 1)
+	if move.Bomb {
+		text += "b"
+	}
+	return text
+}
+
+// parseMove reads a move written by formatMove, checking the column is on
+// the board.
+func parseMove(text string, width int) (types.Move, error) {
+	var move types.Move
+	if strings.HasSuffix(text, "b") {
+		move.Bomb = true
+		text = strings.TrimSuffix(text, "b")
+	}
+	column, err := strconv.Atoi(text)
+	if err != nil || column < 1 || column > width {
+		return types.Move{}, fmt.Errorf("%w: bad move %q", ErrBadReply, text)
+	}
+	move.Column = column - 1
+	return move, nil
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SettingsPath is where the engine settings are kept.
+var SettingsPath = filepath.Join("files", "engine.json")
+
+// Settings say which program plays the External Engine seats. They are
+// kept on this computer rather than in the game settings, so joining or
+// hosting a game never runs a program someone else chose.
+type Settings struct {
+	Command  string        `json:"command"`
+	Args     []string      `json:"args,omitempty"`
+	MoveTime time.Duration `json:"moveTime"` // how long the engine gets per move
+}
+
+// LoadSettings reads the engine settings, or returns the defaults if there
+// are none or they can't be read.
+func LoadSettings() Settings {
+	settings := Settings{MoveTime: DefaultMoveTime}
+	data, err := os.ReadFile(SettingsPath)
+	if err != nil {
+		return settings
+	}
+	json.Unmarshal(data, &settings)
+	return settings
+}
+
+// SaveSettings writes the engine settings.
+func SaveSettings(settings Settings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(SettingsPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(SettingsPath, data, 0644)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/network"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// lanRefreshInterval is how often the list of LAN games is redrawn
+const lanRefreshInterval = 2 * time.Second
+
+// createLANPane builds the tab listing games announced on the local network.
+// Listening starts when the tab is built and lasts as long as the app.
+func createLANPane(a fyne.App) fyne.CanvasObject {
+	// The list is redrawn from the UI while the browser's updates come in
+	// on another goroutine, so games and selectedGame are shared
+	var mu sync.Mutex
+	var games []network.LANGame
+	selectedGame := -1
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Your name")
+	statusLabel := widget.NewLabel("Looking for games...")
+
+	gameList := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(games)
+		},
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			mu.Lock()
+			text := ""
+			if id < len(games) {
+				text = lanGameSummary(games[id])
+			}
+			mu.Unlock()
+			o.(*widget.Label).SetText(text)
+		},
+	)
+	gameList.OnSelected = func(id widget.ListItemID) {
+		mu.Lock()
+		selectedGame = id
+		mu.Unlock()
+	}
+
+	browser, err := network.BrowseLAN(fmt.Sprintf(":%d", network.DefaultDiscoveryPort))
+	if err != nil {
+		statusLabel.SetText("Can't look for LAN games: " + err.Error())
+	} else {
+		go func() {
+			for range time.Tick(lanRefreshInterval) {
+				found := browser.Games()
+				mu.Lock()
+				games = found
+				lost := selectedGame >= len(games)
+				if lost {
+					selectedGame = -1
+				}
+				mu.Unlock()
+
+				// The list reads games back as it redraws, so not while locked
+				if lost {
+					gameList.UnselectAll()
+				}
+				gameList.Refresh()
+				if len(found) == 0 {
+					statusLabel.SetText("Looking for games...")
+				} else {
+					statusLabel.SetText(fmt.Sprintf("%d games found", len(found)))
+				}
+			}
+		}()
+	}
+
+	// selected is the chosen game's address, or "" if nothing is chosen
+	selected := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		if selectedGame < 0 || selectedGame >= len(games) {
+			statusLabel.SetText("Choose a game first")
+			return ""
+		}
+		return games[selectedGame].Addr
+	}
+	joinButton := widget.NewButton("Join Selected Game", func() {
+		addr := selected()
+		if addr == "" {
+			return
+		}
+		client, err := network.Dial(addr, nameEntry.Text)
+		if err != nil {
+			statusLabel.SetText("Could not join: " + err.Error())
+			return
+		}
+		ui.RemoteGameWindow(client, a)
+	})
+	watchButton := widget.NewButton("Watch Selected Game", func() {
+		addr := selected()
+		if addr == "" {
+			return
+		}
+		client, err := network.Spectate(addr, nameEntry.Text)
+		if err != nil {
+			statusLabel.SetText("Could not watch: " + err.Error())
+			return
+		}
+		ui.RemoteGameWindow(client, a)
+	})
+
+	controls := container.NewVBox(
+		widget.NewLabel("Name:"), nameEntry,
+		joinButton, watchButton,
+		statusLabel,
+	)
+	return container.NewBorder(nil, nil, controls, nil, gameList)
+}
+
+// lanGameSummary is the one-line description shown in the LAN game list
+func lanGameSummary(game network.LANGame) string {
+	seats := "full"
+	switch game.FreeSeats {
+	case 0:
+	case 1:
+		seats = "1 free seat"
+	default:
+		seats = fmt.Sprintf("%d free seats", game.FreeSeats)
+	}
+	return fmt.Sprintf("%s (%s) - %s, %s", game.Name, game.Addr, network.RulesSummary(game.Config), seats)
+}
@@ -0,0 +1,180 @@
+// Package learn trains the neural AI's network by self-play. The first
+// generation of games is played by the hard AI; each later one by the
+// network trained so far, so it learns from its own mistakes. Every
+// position is labelled with how the game turned out for each side.
+package learn
+
+import (
+	"errors"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/nn"
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// Games start with a few random moves and make random moves now and then
+// afterwards, so they don't all play out the same. Games still going after
+// maxMovesPerCell moves a cell are left out.
+const (
+	openingMoves    = 2
+	maxMovesPerCell = 4
+)
+
+// ErrTooFewPlayers is returned for a game with nobody to play against.
+var ErrTooFewPlayers = errors.New("self-play needs at least two players")
+
+// Options say what to train for.
+type Options struct {
+	Config      types.GameConfig // the kind of game; every seat is played by the AI
+	Generations int
+	Games       int     // self-play games each generation
+	Hidden      int     // units in the hidden layer
+	Epochs      int     // passes over the positions after each generation
+	Rate        float64 // learning rate
+	Explore     float64 // chance of a random move, after the opening
+	Workers     int     // games played at once; 0 for one per CPU
+	Seed        int64   // the same seed trains the same network
+
+	// Progress, if set, is called after every generation.
+	Progress func(generation, positions int, loss float64)
+}
+
+// Train plays the games and trains a network on them.
+func Train(opts Options) (*nn.Model, error) {
+	config := opts.Config
+	config.BestOf = 1
+	config.PlayerTypes = make([]int, config.PlayerCount)
+	for i := range config.PlayerTypes {
+		config.PlayerTypes[i] = types.NeuralAI
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.PlayerCount < 2 {
+		return nil, ErrTooFewPlayers
+	}
+	if opts.Generations < 1 || opts.Games < 1 || opts.Hidden < 1 || opts.Epochs < 1 {
+		return nil, errors.New("generations, games, hidden units and epochs must all be at least one")
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	model := nn.NewModel(opts.Hidden, rng)
+	var samples []nn.Sample
+	seed := opts.Seed
+	for generation := 1; generation <= opts.Generations; generation++ {
+		player := model
+		if generation == 1 {
+			player = nil // the untrained network would only play at random
+		}
+		samples = append(samples, playGames(config, player, opts, seed)...)
+		seed += int64(opts.Games)
+		model.Loss = model.Fit(samples, opts.Epochs, opts.Rate, rng)
+		if opts.Progress != nil {
+			opts.Progress(generation, len(samples), model.Loss)
+		}
+	}
+
+	model.Config = config
+	model.Games = opts.Generations * opts.Games
+	model.Positions = len(samples)
+	model.Trained = time.Now().UTC().Truncate(time.Second)
+	return model, nil
+}
+
+// playGames plays a generation's games, several at once.
+func playGames(config types.GameConfig, model *nn.Model, opts Options, seed int64) []nn.Sample {
+	games := make([][]nn.Sample, opts.Games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(opts.Workers, opts.Games); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				games[i] = playGame(config, model, opts.Explore, seed+int64(i))
+			}
+		}()
+	}
+	for i := range games {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var samples []nn.Sample
+	for _, game := range games {
+		samples = append(samples, game...)
+	}
+	return samples
+}
+
+// playGame plays one round and returns every position in it, seen from
+// each side, labelled 1 if that side went on to win, -1 if it lost and 0
+// for a draw.
+func playGame(config types.GameConfig, model *nn.Model, explore float64, seed int64) []nn.Sample {
+	gw := ui.NewGameFromConfig(config)
+	gw.SetSeed(seed)
+	level := types.HardAI
+	if model != nil {
+		gw.SetModel(model)
+		level = types.NeuralAI
+	}
+	rng := rand.New(rand.NewSource(seed))
+	// One player from each side, whose point of view is that side's
+	sides := gw.Position(0).Sides
+	var players []int
+	for player, side := range sides {
+		if side == player {
+			players = append(players, player)
+		}
+	}
+
+	type seen struct {
+		side     int
+		features []float64
+	}
+	var positions []seen
+	limit := maxMovesPerCell * config.GridWidth * config.GridHeight
+	for moves := 0; !gw.RoundOver; moves++ {
+		if moves == limit {
+			return nil
+		}
+		choice := level
+		if moves < openingMoves || rng.Float64() < explore {
+			choice = types.EasyAI
+		}
+		if _, err := gw.PlayMove(gw.AIMove(choice)); err != nil {
+			return nil
+		}
+		if gw.RoundOver {
+			break // the search spots wins for itself
+		}
+		for _, player := range players {
+			positions = append(positions, seen{player, nn.Features(gw.Position(player))})
+		}
+	}
+
+	winner := -1
+	if gw.Winners[0] > 0 {
+		winner = sides[gw.Winners[0]-1]
+	}
+	samples := make([]nn.Sample, len(positions))
+	for i, p := range positions {
+		samples[i].Features = p.features
+		switch {
+		case winner == -1:
+		case p.side == winner:
+			samples[i].Result = 1
+		default:
+			samples[i].Result = -1
+		}
+	}
+	return samples
+}
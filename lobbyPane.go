@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/network"
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// createLobbyPane builds the tab for finding and creating rooms in a lobby.
+// New rooms use whatever is currently chosen on the Setup Game tab.
+func createLobbyPane(a fyne.App, currentConfig func() types.GameConfig) fyne.CanvasObject {
+	var lobby *network.LobbyClient
+	var rooms []network.RoomInfo
+	selectedRoom := -1
+
+	addressEntry := widget.NewEntry()
+	addressEntry.SetText(fmt.Sprintf("localhost:%d", network.DefaultLobbyPort))
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Your name")
+	statusLabel := widget.NewLabel("Not connected")
+	roomLabel := widget.NewLabel("")
+
+	roomList := widget.NewList(
+		func() int { return len(rooms) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(roomSummary(rooms[id]))
+		},
+	)
+	roomList.OnSelected = func(id widget.ListItemID) {
+		selectedRoom = id
+	}
+
+	roomNameEntry := widget.NewEntry()
+	roomNameEntry.SetPlaceHolder("Room name")
+	readyCheck := widget.NewCheck("Ready", func(ready bool) {
+		if lobby != nil {
+			lobby.SetReady(ready)
+		}
+	})
+
+	events := network.LobbyEvents{
+		Rooms: func(list []network.RoomInfo) {
+			rooms = list
+			selectedRoom = -1
+			roomList.UnselectAll()
+			roomList.Refresh()
+		},
+		Room: func(room network.RoomInfo) {
+			roomLabel.SetText(roomDetails(room))
+		},
+		GameStart: func(game *network.Client) {
+			ui.RemoteGameWindow(game, a)
+		},
+		Error: func(message string) {
+			statusLabel.SetText(message)
+		},
+	}
+
+	connectButton := widget.NewButton("Connect", func() {
+		if lobby != nil {
+			lobby.Close()
+		}
+		client, err := network.DialLobby(addressEntry.Text, nameEntry.Text, events)
+		if err != nil {
+			statusLabel.SetText("Could not connect: " + err.Error())
+			return
+		}
+		lobby = client
+		statusLabel.SetText("Connected to " + addressEntry.Text)
+	})
+	createButton := widget.NewButton("Create Room from Setup", func() {
+		if lobby != nil {
+			lobby.CreateRoom(roomNameEntry.Text, currentConfig())
+		}
+	})
+	joinButton := widget.NewButton("Join Selected Room", func() {
+		if lobby != nil && selectedRoom >= 0 && selectedRoom < len(rooms) {
+			lobby.JoinRoom(rooms[selectedRoom].ID, -1)
+		}
+	})
+	watchButton := widget.NewButton("Watch Selected Room", func() {
+		if lobby != nil && selectedRoom >= 0 && selectedRoom < len(rooms) {
+			lobby.WatchRoom(rooms[selectedRoom].ID)
+		}
+	})
+	leaveButton := widget.NewButton("Leave Room", func() {
+		if lobby != nil {
+			lobby.LeaveRoom()
+			readyCheck.SetChecked(false)
+			roomLabel.SetText("")
+		}
+	})
+	refreshButton := widget.NewButton("Refresh", func() {
+		if lobby != nil {
+			lobby.ListRooms()
+		}
+	})
+
+	controls := container.NewVBox(
+		widget.NewLabel("Lobby Address:"), addressEntry,
+		widget.NewLabel("Name:"), nameEntry,
+		connectButton,
+		statusLabel,
+		roomNameEntry, createButton,
+		joinButton, watchButton, refreshButton,
+		readyCheck, leaveButton,
+		roomLabel,
+	)
+	return container.NewBorder(nil, nil, controls, nil, roomList)
+}
+
+// roomSummary is the one-line description shown in the room list
+func roomSummary(room network.RoomInfo) string {
+	taken := 0
+	for _, seat := range room.Seats {
+		if seat.Taken || seat.AI {
+			taken++
+		}
+	}
+	status := "open"
+	if room.Started {
+		status = "playing"
+	}
+	if room.Watchers > 0 {
+		status += fmt.Sprintf(", %d watching", room.Watchers)
+	}
+	return fmt.Sprintf("%s - %dx%d, line %d, %d/%d seats, %s", room.Name, room.Config.GridWidth, room.Config.GridHeight, room.Config.LineLength, taken, len(room.Seats), status)
+}
+
+// roomDetails lists who is sitting where in the room we are in
+func roomDetails(room network.RoomInfo) string {
+	text := room.Name + ":\n"
+	for i, seat := range room.Seats {
+		switch {
+		case seat.AI:
+			text += fmt.Sprintf("Player %d: AI\n", i+1)
+		case seat.Taken && seat.Ready:
+			text += fmt.Sprintf("Player %d: %s (ready)\n", i+1, seat.Player)
+		case seat.Taken:
+			text += fmt.Sprintf("Player %d: %s\n", i+1, seat.Player)
+		case room.Config.AIForMissing:
+			text += fmt.Sprintf("Player %d: empty (AI if nobody joins)\n", i+1)
+		default:
+			text += fmt.Sprintf("Player %d: empty\n", i+1)
+		}
+	}
+	return text
+}
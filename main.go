@@ -2,27 +2,37 @@ package main
 
 import (
 	"fmt"
+	"net"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/arena"
+	"insighthub.uk/connectron/v2/engine"
+	"insighthub.uk/connectron/v2/network"
 	"insighthub.uk/connectron/v2/saves"
+	"insighthub.uk/connectron/v2/types"
 	"insighthub.uk/connectron/v2/ui"
 )
 
-const (
-	EasyAI   = iota
-	MediumAI
-	HardAI
-)
-
 var Alliances = map[string][]string{}
 var unassigned []string
 
 func main() {
+	ui.EngineSettings = engine.LoadSettings()
+
+	// Command-line tools run without opening any windows
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
 	// Initialize the application
 	connectronApp := app.New()
 	connectronApp.Settings().SetTheme(theme.LightTheme())
@@ -34,7 +44,10 @@ func main() {
 
 	// Create menu items
 	menu := fyne.NewMainMenu(
-		fyne.NewMenu("File"),
+		fyne.NewMenu("File",
+			fyne.NewMenuItem("Open Saved Game...", func() { openSavedGame(connectronApp, mainWindow, false) }),
+			fyne.NewMenuItem("Open Replay...", func() { openSavedGame(connectronApp, mainWindow, true) }),
+		),
 		fyne.NewMenu("Edit",
 			fyne.NewMenuItem("Settings", func() { ShowSettingsWindow(connectronApp) }),
 		),
@@ -75,25 +88,77 @@ func main() {
 	// Player Dropdowns Container
 	playerDropdownsContainer := container.NewVBox()
 	playerTypes := make([]int, 10)
+	personalities := make([]string, 10)
+
+	// Each AI option shows its rating from the last arena run, if there was one
+	calibration, _ := arena.ReadCalibration(arena.CalibrationPath)
+	playerOptions := []struct {
+		label      string
+		playerType int
+	}{
+		{"Easy AI", types.EasyAI},
+		{"Medium AI", types.MediumAI},
+		{"Hard AI", types.HardAI},
+		{"External Engine", types.ExternalEngine},
+		{"Neural AI", types.NeuralAI},
+		{"Adaptive AI", types.AdaptiveAI},
+		{"Person", types.HumanPlayer},
+		{"Remote", types.RemotePlayer},
+	}
+	var options []string
+	optionTypes := make(map[string]int)
+	typeLabels := make(map[int]string)
+	for _, option := range playerOptions {
+		label := option.label
+		if rating, ok := calibration.Rating(option.playerType); ok {
+			label = fmt.Sprintf("%s (rated %.0f)", label, rating.Rating)
+		}
+		options = append(options, label)
+		optionTypes[label] = option.playerType
+		typeLabels[option.playerType] = label
+	}
+	for i := range playerTypes {
+		playerTypes[i] = types.HumanPlayer
+	}
+
+	// Each AI seat that searches ahead can have its own play style. The
+	// corner hunter is only offered with the corner bonus on.
+	cornerBonusCheckbox := widget.NewCheck("Enable Corner Bonus", nil)
+	styleNames := make(map[string]string)
+	styleOptions := func() []string {
+		var labels []string
+		for _, name := range types.Personalities {
+			if name == "corner" && !cornerBonusCheckbox.Checked {
+				continue
+			}
+			label := ui.FindPersonality(name).Label
+			labels = append(labels, label)
+			styleNames[label] = name
+		}
+		return labels
+	}
 
 	updatePlayerDropdowns := func(count int) {
 		playerDropdownsContainer.RemoveAll()
+		styles := styleOptions()
 		for i := 0; i < count; i++ {
-			options := []string{"Easy AI", "Medium AI", "Hard AI", "Person"}
+			if personalities[i] == "corner" && !cornerBonusCheckbox.Checked {
+				personalities[i] = ""
+			}
+			styleDropdown := widget.NewSelect(styles, func(selected string) {
+				personalities[i] = styleNames[selected]
+			})
+			styleDropdown.SetSelected(ui.FindPersonality(personalities[i]).Label)
 			dropdown := widget.NewSelect(options, func(selected string) {
-				switch selected {
-				case "Easy AI":
-					playerTypes[i] = EasyAI
-				case "Medium AI":
-					playerTypes[i] = MediumAI
-				case "Hard AI":
-					playerTypes[i] = HardAI
-				case "Person":
-					playerTypes[i] = -1
+				playerTypes[i] = optionTypes[selected]
+				if ui.HasPersonality(playerTypes[i]) {
+					styleDropdown.Enable()
+				} else {
+					styleDropdown.Disable()
 				}
 			})
-			dropdown.SetSelected("Person")
-			playerDropdownsContainer.Add(container.NewHBox(widget.NewLabel(fmt.Sprintf("Player %d:", i+1)), dropdown))
+			dropdown.SetSelected(typeLabels[playerTypes[i]])
+			playerDropdownsContainer.Add(container.NewHBox(widget.NewLabel(fmt.Sprintf("Player %d:", i+1)), dropdown, styleDropdown))
 		}
 		playerDropdownsContainer.Refresh()
 	}
@@ -104,11 +169,31 @@ func main() {
 		updatePlayerDropdowns(int(value))
 	}
 
+	// Hints the people playing may ask the AI for
+	hintBudgetLabel := widget.NewLabel("Hints per Person each Game (0-10):")
+	hintBudgetValue := widget.NewLabel(strconv.Itoa(ui.DefaultHintBudget))
+	hintBudgetSlider := widget.NewSlider(0, 10)
+	hintBudgetSlider.SetValue(ui.DefaultHintBudget)
+	hintBudgetSlider.OnChanged = func(value float64) {
+		hintBudgetValue.SetText(fmt.Sprintf("%d", int(value)))
+	}
+
 	// Missing player AI Configuration
 	aiForMissingCheckbox := widget.NewCheck("AI for Missing Players", nil)
 
+	// Port to host on when any player is Remote
+	hostPortLabel := widget.NewLabel("Host Port (for Remote players):")
+	hostPortEntry := widget.NewEntry()
+	hostPortEntry.SetText(strconv.Itoa(network.DefaultPort))
+	spectatorDelayLabel := widget.NewLabel("Spectator Delay (seconds):")
+	spectatorDelayEntry := widget.NewEntry()
+	spectatorDelayEntry.SetText(strconv.Itoa(int(network.DefaultSpectatorDelay.Seconds())))
+	gracePeriodLabel := widget.NewLabel("Reconnect Grace (seconds):")
+	gracePeriodEntry := widget.NewEntry()
+	gracePeriodEntry.SetText(strconv.Itoa(int(network.DefaultGracePeriod.Seconds())))
+
 	// Special Rule Options
-	cornerBonusCheckbox := widget.NewCheck("Enable Corner Bonus", nil)
+	cornerBonusCheckbox.OnChanged = func(bool) { updatePlayerDropdowns(int(playerCountSlider.Value)) }
 	solitaireRuleCheckbox := widget.NewCheck("Enable Solitaire Destruction", nil)
 	bombCounterCheckbox := widget.NewCheck("Enable Bomb Counter", nil)
 	overflowRuleCheckbox := widget.NewCheck("Enable Overflow Rule", nil)
@@ -129,7 +214,11 @@ func main() {
 	playerSettings := container.NewVBox(
 		playerCountLabel, playerCountSlider, playerCountValue,
 		playerDropdownsContainer,
+		hintBudgetLabel, hintBudgetSlider, hintBudgetValue,
 		aiForMissingCheckbox,
+		hostPortLabel, hostPortEntry,
+		spectatorDelayLabel, spectatorDelayEntry,
+		gracePeriodLabel, gracePeriodEntry,
 	)
 
 	ruleSettings := container.NewVBox(
@@ -141,6 +230,32 @@ func main() {
 		allianceSetupButton,
 	)
 
+	// The current setup as game settings, used when creating lobby rooms
+	currentConfig := func() types.GameConfig {
+		var alliancesSlice [][]string
+		for _, players := range Alliances {
+			alliancesSlice = append(alliancesSlice, players)
+		}
+		playerCount := int(playerCountSlider.Value)
+		return types.GameConfig{
+			GridWidth:       int(gridWidthSlider.Value),
+			GridHeight:      int(gridHeightSlider.Value),
+			LineLength:      int(lineLengthSlider.Value),
+			PlayerCount:     playerCount,
+			BestOf:          1,
+			PlayerTypes:     append([]int(nil), playerTypes[:playerCount]...),
+			AIForMissing:    aiForMissingCheckbox.Checked,
+			CornerBonus:     cornerBonusCheckbox.Checked,
+			SolitaireRule:   solitaireRuleCheckbox.Checked,
+			BombCounter:     bombCounterCheckbox.Checked,
+			OverflowRule:    overflowRuleCheckbox.Checked,
+			EnableAlliances: allianceRuleCheckbox.Checked,
+			Alliances:       alliancesSlice,
+			Personalities:   append([]string(nil), personalities[:playerCount]...),
+			HintBudget:      int(hintBudgetSlider.Value),
+		}
+	}
+
 	// Start Game Button
 	startGameButton := widget.NewButton("Start Game", func() {
 		bestOfConverted, _ := strconv.Atoi("1")
@@ -149,7 +264,7 @@ func main() {
 		for _, players := range Alliances {
 			alliancesSlice = append(alliancesSlice, players)
 		}
-		startGameSetup(int(gridWidthSlider.Value), int(gridHeightSlider.Value), int(lineLengthSlider.Value), int(playerCountSlider.Value), allianceRuleCheckbox.Checked, playerTypes, bestOfConverted, cornerBonusCheckbox.Checked, solitaireRuleCheckbox.Checked, bombCounterCheckbox.Checked, overflowRuleCheckbox.Checked, aiForMissingCheckbox.Checked, alliancesSlice)
+		startGameSetup(int(gridWidthSlider.Value), int(gridHeightSlider.Value), int(lineLengthSlider.Value), int(playerCountSlider.Value), allianceRuleCheckbox.Checked, playerTypes, personalities, int(hintBudgetSlider.Value), bestOfConverted, cornerBonusCheckbox.Checked, solitaireRuleCheckbox.Checked, bombCounterCheckbox.Checked, overflowRuleCheckbox.Checked, aiForMissingCheckbox.Checked, alliancesSlice, hostPortEntry.Text, spectatorDelayEntry.Text, gracePeriodEntry.Text)
 	})
 
 	leftPane := container.NewVBox(
@@ -162,10 +277,16 @@ func main() {
 	)
 
 
-	leaderboardData, _ := saves.ReadCSV("files/leaderboard.csv")
+	leaderboardData, _ := ui.ReadLeaderboard()
 	// Main Tabs
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Setup Game", leftPane),
+		container.NewTabItem("Join Game", createJoinPane(connectronApp)),
+		container.NewTabItem("Join LAN Game", createLANPane(connectronApp)),
+		container.NewTabItem("Lobby", createLobbyPane(connectronApp, currentConfig)),
+		container.NewTabItem("Correspondence", createCorrespondencePane(connectronApp, mainWindow, currentConfig)),
+		container.NewTabItem("Tournament", createTournamentPane(connectronApp, mainWindow, currentConfig)),
+		container.NewTabItem("Puzzles", createPuzzlePane(connectronApp)),
 		container.NewTabItem("Leaderboard", ui.CreateLeaderboard(leaderboardData)),
 	)
 	
@@ -177,8 +298,37 @@ func main() {
 // ShowSettingsWindow creates a simple settings window
 func ShowSettingsWindow(a fyne.App) {
 	win := a.NewWindow("Settings")
+
+	// The program that plays External Engine seats
+	engineEntry := widget.NewEntry()
+	engineEntry.SetPlaceHolder("e.g. python3 mybot.py")
+	engineEntry.SetText(strings.TrimSpace(ui.EngineSettings.Command + " " + strings.Join(ui.EngineSettings.Args, " ")))
+	moveTimeEntry := widget.NewEntry()
+	moveTimeEntry.SetText(strconv.FormatInt(ui.EngineSettings.MoveTime.Milliseconds(), 10))
+	engineStatus := widget.NewLabel("")
+	saveEngineButton := widget.NewButton("Save Engine", func() {
+		moveTime, err := strconv.Atoi(moveTimeEntry.Text)
+		if err != nil || moveTime <= 0 {
+			engineStatus.SetText("Move time must be a whole number of milliseconds")
+			return
+		}
+		settings := engine.Settings{MoveTime: time.Duration(moveTime) * time.Millisecond}
+		if command := strings.Fields(engineEntry.Text); len(command) > 0 {
+			settings.Command, settings.Args = command[0], command[1:]
+		}
+		if err := engine.SaveSettings(settings); err != nil {
+			engineStatus.SetText("Could not save: " + err.Error())
+			return
+		}
+		ui.EngineSettings = settings
+		engineStatus.SetText("Saved. New games will use this engine.")
+	})
+
 	win.SetContent(container.NewVBox(
-		widget.NewLabel("Settings Window"),
+		widget.NewLabel("External Engine command:"), engineEntry,
+		widget.NewLabel("Engine move time (ms):"), moveTimeEntry,
+		saveEngineButton,
+		engineStatus,
 		widget.NewButton("Close", func() {
 			win.Close()
 		}),
@@ -188,14 +338,176 @@ func ShowSettingsWindow(a fyne.App) {
 }
 
 // startGameSetup initiates the game setup based on selected settings
-func startGameSetup(gridWidth, gridHeight, lineLength, playerCount int, enableAlliances bool, playerTypes []int, bestOf int, cornerBonus, solitaireRule, bombCounter, overflowRule, aiForMissing bool, alliances [][]string) {
+func startGameSetup(gridWidth, gridHeight, lineLength, playerCount int, enableAlliances bool, playerTypes []int, personalities []string, hintBudget, bestOf int, cornerBonus, solitaireRule, bombCounter, overflowRule, aiForMissing bool, alliances [][]string, hostPort, spectatorDelay, gracePeriod string) {
 	// Create and configure the game instance here (this part is a placeholder)
 	game := ui.NewGame(gridWidth, gridHeight, playerCount, lineLength, 0, bestOf, playerTypes, aiForMissing, cornerBonus, solitaireRule, bombCounter, overflowRule, enableAlliances, alliances)
+	game.Personalities = append([]string(nil), personalities[:playerCount]...)
+	game.HintBudget = hintBudget
+
+	// Games with remote players are hosted, and this window joins like everyone else
+	for _, playerType := range playerTypes[:playerCount] {
+		if playerType == types.RemotePlayer {
+			hostGame(game, hostPort, spectatorDelay, gracePeriod)
+			return
+		}
+	}
 
 	// Display the main game window
 	ui.MainGameWindow(game, fyne.CurrentApp())
 }
 
+// hostedSession is the host's own connection to the game it is hosting.
+// Leaving the game shuts the server down.
+type hostedSession struct {
+	*network.Client
+	server    *network.Server
+	announcer *network.Announcer // nil if the game couldn't be announced
+}
+
+// Hosting tells the game window this machine runs the server, so it is
+// the one to record the series.
+func (h hostedSession) Hosting() bool { return true }
+
+func (h hostedSession) Close() error {
+	if h.announcer != nil {
+		h.announcer.Stop()
+	}
+	h.server.Close()
+	return h.Client.Close()
+}
+
+// runWindow starts the desktop app with open showing its first window,
+// for commands that skip the setup screen.
+func runWindow(open func(a fyne.App)) {
+	connectronApp := app.New()
+	connectronApp.Settings().SetTheme(theme.LightTheme())
+	open(connectronApp)
+	connectronApp.Run()
+}
+
+// openSavedGame asks for a saved game and carries it on, or replays it.
+// A finished series can only be replayed.
+func openSavedGame(a fyne.App, parent fyne.Window, replay bool) {
+	dialog.ShowFileOpen(func(file fyne.URIReadCloser, err error) {
+		if err != nil || file == nil {
+			return
+		}
+		file.Close()
+		record, err := saves.ReadGame(file.URI().Path())
+		if err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		gw, err := ui.LoadGame(record)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("the saved game doesn't follow the rules: %w", err), parent)
+			return
+		}
+		if replay || gw.SeriesOver() {
+			ui.ReplayWindow(record, a)
+			return
+		}
+		if hasRemotePlayers(gw) {
+			hostGame(gw, strconv.Itoa(network.DefaultPort), strconv.Itoa(int(network.DefaultSpectatorDelay.Seconds())), strconv.Itoa(int(network.DefaultGracePeriod.Seconds())))
+			return
+		}
+		if gw.RoundOver {
+			gw = gw.NextRound() // saved just as a round finished
+		}
+		ui.MainGameWindow(gw, a)
+	}, parent)
+}
+
+// hostGame starts a server for the game on the given port and opens the host's window
+func hostGame(game *ui.Game, hostPort, spectatorDelay, gracePeriod string) {
+	delaySeconds, err := strconv.Atoi(spectatorDelay)
+	if err != nil || delaySeconds < 0 {
+		showError("Spectator delay must be a whole number of seconds")
+		return
+	}
+	graceSeconds, err := strconv.Atoi(gracePeriod)
+	if err != nil || graceSeconds < 0 {
+		showError("Reconnect grace must be a whole number of seconds")
+		return
+	}
+	listener, err := net.Listen("tcp", ":"+hostPort)
+	if err != nil {
+		showError("Could not host game: " + err.Error())
+		return
+	}
+	server := network.NewServer(game)
+	server.SetSpectatorDelay(time.Duration(delaySeconds) * time.Second)
+	server.SetGracePeriod(time.Duration(graceSeconds) * time.Second)
+	go server.Serve(listener)
+
+	client, err := server.LocalClient("Host")
+	if err != nil {
+		server.Close()
+		showError("Could not join hosted game: " + err.Error())
+		return
+	}
+
+	// Let players on the LAN find the game without typing in an address
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "Connectron"
+	}
+	announcer, err := server.Announce(hostname+"'s game", listener.Addr().(*net.TCPAddr).Port, network.DefaultAnnounceAddr)
+	if err != nil {
+		fmt.Println("Could not announce game on the LAN:", err)
+	}
+	ui.RemoteGameWindow(hostedSession{client, server, announcer}, fyne.CurrentApp())
+}
+
+// createJoinPane builds the tab for joining a game hosted on another machine
+func createJoinPane(a fyne.App) fyne.CanvasObject {
+	addressEntry := widget.NewEntry()
+	addressEntry.SetPlaceHolder(fmt.Sprintf("host:%d", network.DefaultPort))
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Your name")
+	statusLabel := widget.NewLabel("")
+
+	joinButton := widget.NewButton("Join", func() {
+		statusLabel.SetText("Connecting...")
+		client, err := network.Dial(addressEntry.Text, nameEntry.Text)
+		if err != nil {
+			statusLabel.SetText("Could not join: " + err.Error())
+			return
+		}
+		statusLabel.SetText("")
+		ui.RemoteGameWindow(client, a)
+	})
+	watchButton := widget.NewButton("Watch", func() {
+		statusLabel.SetText("Connecting...")
+		client, err := network.Spectate(addressEntry.Text, nameEntry.Text)
+		if err != nil {
+			statusLabel.SetText("Could not watch: " + err.Error())
+			return
+		}
+		statusLabel.SetText("")
+		ui.RemoteGameWindow(client, a)
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Host Address:"), addressEntry,
+		widget.NewLabel("Name:"), nameEntry,
+		container.NewHBox(joinButton, watchButton),
+		statusLabel,
+	)
+}
+
+// showError pops up a small window with an error message
+func showError(message string) {
+	win := fyne.CurrentApp().NewWindow("Error")
+	win.SetContent(container.NewVBox(
+		widget.NewLabel(message),
+		widget.NewButton("Close", func() {
+			win.Close()
+		}),
+	))
+	win.Show()
+}
+
 func showAlliancesWindow(a fyne.App, playerCountSlider *widget.Slider) {
 	win := a.NewWindow("Configure Alliances")
 
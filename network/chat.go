@@ -0,0 +1,100 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// handleChat passes a chat message on to everyone allowed to read it and
+// keeps it in the game's log.
+func (s *Server) handleChat(c *client, chat *types.ChatMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkChat(c, chat); err != nil {
+		c.conn.Send(Message{Type: MsgError, Error: err.Error()})
+		return
+	}
+	msg := types.ChatMessage{
+		Round:    s.game.RoundCount,
+		Move:     len(s.game.Moves),
+		Player:   chat.Player,
+		Name:     c.name,
+		Text:     strings.TrimSpace(chat.Text),
+		Alliance: chat.Alliance,
+	}
+	s.game.Chat = append(s.game.Chat, msg)
+
+	for cl := range s.clients {
+		if s.canRead(cl.seats, msg) {
+			cl.conn.Send(Message{Type: MsgChat, Chat: &msg})
+		}
+	}
+	if msg.Alliance {
+		return
+	}
+	// Spectators get it when they see the board it was said over
+	for sp := range s.spectators {
+		select {
+		case sp.queue <- timedState{at: time.Now(), chat: &msg}:
+		default:
+			sp.conn.Close()
+		}
+	}
+}
+
+// checkChat says what, if anything, is wrong with a message a client sent.
+// Must be called with s.mu held.
+func (s *Server) checkChat(c *client, chat *types.ChatMessage) error {
+	if chat == nil {
+		return fmt.Errorf("chat message without any text")
+	}
+	text := strings.TrimSpace(chat.Text)
+	if text == "" {
+		return fmt.Errorf("chat messages can't be empty")
+	}
+	if utf8.RuneCountInString(text) > types.MaxChatLength {
+		return fmt.Errorf("chat messages can be at most %d characters", types.MaxChatLength)
+	}
+	if chat.Alliance && !s.game.EnableAlliances {
+		return fmt.Errorf("alliances aren't enabled in this game")
+	}
+	for _, seat := range c.seats {
+		if seat == chat.Player {
+			return nil
+		}
+	}
+	return fmt.Errorf("you can only chat as your own player")
+}
+
+// canRead reports whether a client playing the given seats may read a
+// message. Alliance messages only go to the sender's allies. Must be called
+// with s.mu held.
+func (s *Server) canRead(seats []int, msg types.ChatMessage) bool {
+	if !msg.Alliance {
+		return true
+	}
+	config := s.game.Config()
+	for _, seat := range seats {
+		if seat == msg.Player || config.Allied(seat, msg.Player) {
+			return true
+		}
+	}
+	return false
+}
+
+// chatHistory is everything said so far that a client playing the given
+// seats may read. Must be called with s.mu held.
+func (s *Server) chatHistory(seats []int) []types.ChatMessage {
+	var history []types.ChatMessage
+	for _, msg := range s.game.Chat {
+		if s.canRead(seats, msg) {
+			history = append(history, msg)
+		}
+	}
+	return history
+}
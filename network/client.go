@@ -0,0 +1,330 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+const dialTimeout = 5 * time.Second
+
+// chatQueue is how many chat messages can wait for the window before the
+// newest are dropped.
+const chatQueue = 256
+
+// How hard a player whose connection drops tries to get back in. This is
+// longer than the host's grace period because a seat can still be taken
+// back from the AI after it.
+const (
+	reconnectTimeout  = 5 * time.Minute
+	reconnectInterval = 2 * time.Second
+)
+
+// Client is a player's connection to a hosted game. It satisfies
+// ui.RemoteSession so it can be handed straight to the game window.
+type Client struct {
+	mu       sync.Mutex
+	conn     *Conn
+	seats    []int
+	config   types.GameConfig
+	updates  chan types.GameState
+	notices  chan string
+	chat     chan types.ChatMessage
+	chatRead int             // chat messages received, so a reconnect only passes on new ones
+	leave    func() error    // set when playing through a lobby
+	state    types.GameState // the last state received, for applying deltas
+	closed   bool
+
+	// For getting back in after a dropped connection
+	name   string
+	token  string
+	redial func() (net.Conn, error)
+}
+
+// Dial joins the game hosted at addr (host:port). If the connection drops
+// the client dials again and reclaims its seats.
+func Dial(addr, name string) (*Client, error) {
+	redial := func() (net.Conn, error) { return net.DialTimeout("tcp", addr, dialTimeout) }
+	c, err := redial()
+	if err != nil {
+		return nil, err
+	}
+	cl, err := NewClient(c, name)
+	if err != nil {
+		return nil, err
+	}
+	cl.mu.Lock()
+	cl.redial = redial
+	cl.mu.Unlock()
+	return cl, nil
+}
+
+// Spectate watches the game hosted at addr without taking a seat.
+func Spectate(addr, name string) (*Client, error) {
+	c, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpectator(c, name)
+}
+
+// NewClient says hello over an existing connection and waits to be given a seat.
+func NewClient(c net.Conn, name string) (*Client, error) {
+	return handshake(c, Message{Type: MsgHello, Version: ProtocolVersion, Name: name})
+}
+
+// NewSpectator says hello as a spectator over an existing connection.
+func NewSpectator(c net.Conn, name string) (*Client, error) {
+	return handshake(c, Message{Type: MsgHello, Version: ProtocolVersion, Name: name, Watch: true})
+}
+
+func handshake(c net.Conn, hello Message) (*Client, error) {
+	conn := NewConn(c)
+	welcome, err := exchangeHello(conn, hello)
+	if err != nil {
+		return nil, err
+	}
+	cl := newClient(conn, welcome)
+	cl.name = hello.Name
+	go cl.read()
+	return cl, nil
+}
+
+// errRefused is wrapped around errors the host sent back, as opposed to
+// network failures that are worth retrying.
+var errRefused = errors.New("refused by host")
+
+// exchangeHello sends a hello and waits for the welcome, closing the
+// connection if there isn't one.
+func exchangeHello(conn *Conn, hello Message) (Message, error) {
+	if err := conn.Send(hello); err != nil {
+		conn.Close()
+		return Message{}, err
+	}
+	welcome, err := conn.Receive()
+	if err != nil {
+		conn.Close()
+		return Message{}, err
+	}
+	if welcome.Type == MsgError {
+		conn.Close()
+		return Message{}, fmt.Errorf("%w: %s", errRefused, welcome.Error)
+	}
+	if welcome.Type != MsgWelcome || welcome.Config == nil || welcome.State == nil {
+		conn.Close()
+		return Message{}, errors.New("host did not send a welcome")
+	}
+	return welcome, nil
+}
+
+// newClient sets up a client from the host's welcome. Something else must
+// feed it messages, either read or a lobby connection.
+func newClient(conn *Conn, welcome Message) *Client {
+	cl := &Client{
+		conn:    conn,
+		seats:   welcome.Seats,
+		config:  *welcome.Config,
+		updates: make(chan types.GameState, 16),
+		notices: make(chan string, 16),
+		chat:    make(chan types.ChatMessage, chatQueue),
+		token:   welcome.Token,
+	}
+	cl.pushState(*welcome.State)
+	cl.pushChat(welcome.ChatHistory)
+	return cl
+}
+
+func (c *Client) read() {
+	defer close(c.updates)
+	for {
+		msg, err := c.currentConn().Receive()
+		if err != nil {
+			if !c.reconnect() {
+				return
+			}
+			continue
+		}
+		c.handle(msg)
+	}
+}
+
+func (c *Client) currentConn() *Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn
+}
+
+// reconnect keeps trying to get back into the game after the connection
+// drops, using the token from the welcome to reclaim our seats.
+func (c *Client) reconnect() bool {
+	c.mu.Lock()
+	redial, token, closed := c.redial, c.token, c.closed
+	c.mu.Unlock()
+	if redial == nil || token == "" || closed {
+		return false
+	}
+
+	c.notice("Connection lost, reconnecting...")
+	for deadline := time.Now().Add(reconnectTimeout); time.Now().Before(deadline); time.Sleep(reconnectInterval) {
+		nc, err := redial()
+		if err != nil {
+			continue
+		}
+		conn := NewConn(nc)
+		welcome, err := exchangeHello(conn, Message{Type: MsgHello, Version: ProtocolVersion, Name: c.name, Token: token})
+		if errors.Is(err, errRefused) {
+			c.notice(err.Error())
+			return false
+		}
+		if err != nil {
+			continue
+		}
+
+		if c.isClosed() {
+			conn.Close()
+			return false
+		}
+		c.resumed(conn, welcome)
+		return true
+	}
+	return false
+}
+
+func (c *Client) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// resumed switches to a new connection after the host welcomed us back.
+func (c *Client) resumed(conn *Conn, welcome Message) {
+	c.mu.Lock()
+	c.conn, c.seats, c.token = conn, welcome.Seats, welcome.Token
+	c.mu.Unlock()
+	c.pushState(*welcome.State)
+	if c.chatRead < len(welcome.ChatHistory) {
+		c.pushChat(welcome.ChatHistory[c.chatRead:]) // said while we were away
+	}
+	c.notice("Reconnected!")
+}
+
+// notice passes a message to the window, dropping it if the window is behind.
+func (c *Client) notice(message string) {
+	select {
+	case c.notices <- message:
+	default:
+	}
+}
+
+// handle deals with one message from the host.
+func (c *Client) handle(msg Message) {
+	switch msg.Type {
+	case MsgState:
+		if msg.State != nil {
+			c.pushState(*msg.State)
+		}
+	case MsgDelta:
+		if msg.State != nil {
+			state := *msg.State
+			state.Grid = make([][]int, len(c.state.Grid))
+			for row := range c.state.Grid {
+				state.Grid[row] = append([]int(nil), c.state.Grid[row]...)
+			}
+			for _, cell := range msg.Cells {
+				if cell.Row >= 0 && cell.Row < len(state.Grid) && cell.Column >= 0 && cell.Column < len(state.Grid[cell.Row]) {
+					state.Grid[cell.Row][cell.Column] = cell.Player
+				}
+			}
+			c.pushState(state)
+		}
+	case MsgChat:
+		if msg.Chat != nil {
+			c.pushChat([]types.ChatMessage{*msg.Chat})
+		}
+	case MsgError:
+		c.notice(msg.Error)
+	}
+}
+
+// pushChat queues chat messages for the window.
+func (c *Client) pushChat(messages []types.ChatMessage) {
+	for _, msg := range messages {
+		c.chatRead++
+		select {
+		case c.chat <- msg:
+		default:
+		}
+	}
+}
+
+// pushState queues a state for the window. States are full snapshots, so if
+// the window falls behind the oldest one is thrown away rather than blocking
+// the host.
+func (c *Client) pushState(state types.GameState) {
+	c.state = state
+	for {
+		select {
+		case c.updates <- state:
+			return
+		default:
+			select {
+			case <-c.updates:
+			default:
+			}
+		}
+	}
+}
+
+// Config returns the settings of the hosted game.
+func (c *Client) Config() types.GameConfig { return c.config }
+
+// Seats returns the seats this client plays.
+func (c *Client) Seats() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seats
+}
+
+// Token returns the token that reclaims our seats after a dropped connection.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// SendMove asks the host to play a move. Illegal moves come back as notices.
+func (c *Client) SendMove(move types.Move) error {
+	return c.currentConn().Send(Message{Type: MsgMove, Move: &move})
+}
+
+// Updates delivers a new state every time the game changes. It is closed
+// when the connection drops for good.
+func (c *Client) Updates() <-chan types.GameState { return c.updates }
+
+// Notices delivers messages from the host such as rejected moves.
+func (c *Client) Notices() <-chan string { return c.notices }
+
+// SendChat says something to the other players. The host checks it and
+// sends it back to everyone who may read it, including us.
+func (c *Client) SendChat(chat types.ChatMessage) error {
+	return c.currentConn().Send(Message{Type: MsgChat, Chat: &chat})
+}
+
+// Chat delivers what the other players say, starting with everything said
+// before we joined.
+func (c *Client) Chat() <-chan types.ChatMessage { return c.chat }
+
+// Close leaves the game. Games joined through a lobby just leave the room.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	if c.leave != nil {
+		return c.leave()
+	}
+	return c.currentConn().Close()
+}
@@ -0,0 +1,224 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// DefaultDiscoveryPort is the UDP port hosts announce their games on.
+const DefaultDiscoveryPort = 4749
+
+// DefaultAnnounceAddr broadcasts announcements to the whole local network.
+var DefaultAnnounceAddr = fmt.Sprintf("255.255.255.255:%d", DefaultDiscoveryPort)
+
+const (
+	announceInterval = time.Second
+	announceExpiry   = 5 * time.Second // games not heard from in this long are dropped
+	maxAnnounceSize  = 4096
+)
+
+// Announcement is what a host broadcasts about its game.
+type Announcement struct {
+	Version   int              `json:"version"`
+	Name      string           `json:"name"`
+	Port      int              `json:"port"` // the game's TCP port on the announcing machine
+	Config    types.GameConfig `json:"config"`
+	FreeSeats int              `json:"freeSeats"`
+}
+
+// LANGame is a game heard about on the local network.
+type LANGame struct {
+	Announcement
+	Addr string // host:port to Dial
+	seen time.Time
+}
+
+// Announcer tells the local network about a hosted game until stopped.
+type Announcer struct {
+	server *Server
+	name   string
+	port   int
+	conn   net.PacketConn
+	to     net.Addr
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// Announce starts broadcasting the server's game to addr (normally
+// DefaultAnnounceAddr). port is the TCP port the server is listening on.
+func (s *Server) Announce(name string, port int, addr string) (*Announcer, error) {
+	to, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	a := &Announcer{server: s, name: name, port: port, conn: conn, to: to, stop: make(chan struct{})}
+	go a.run()
+	return a, nil
+}
+
+func (a *Announcer) run() {
+	ticker := time.NewTicker(announceInterval)
+	defer ticker.Stop()
+	for {
+		a.send()
+		select {
+		case <-ticker.C:
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+func (a *Announcer) send() {
+	a.server.mu.Lock()
+	if a.server.closed {
+		a.server.mu.Unlock()
+		a.Stop()
+		return
+	}
+	msg := Announcement{
+		Version:   ProtocolVersion,
+		Name:      a.name,
+		Port:      a.port,
+		Config:    a.server.game.Config(),
+		FreeSeats: a.server.freeSeats(),
+	}
+	a.server.mu.Unlock()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	a.conn.WriteTo(data, a.to) // a missed announcement is made up for next time
+}
+
+// Stop stops announcing the game.
+func (a *Announcer) Stop() {
+	a.once.Do(func() {
+		close(a.stop)
+		a.conn.Close()
+	})
+}
+
+// freeSeats counts the seats a new remote player could take. Must be called
+// with s.mu held.
+func (s *Server) freeSeats() int {
+	free := 0
+	for seat, playerType := range s.game.PlayerTypes {
+		if playerType == types.RemotePlayer && s.seats[seat] == nil && !s.held(seat) {
+			free++
+		}
+	}
+	return free
+}
+
+// LANBrowser listens for games announced on the local network.
+type LANBrowser struct {
+	conn  net.PacketConn
+	mu    sync.Mutex
+	games map[string]LANGame // addr -> game
+}
+
+// BrowseLAN starts listening for announcements on addr (normally
+// ":DefaultDiscoveryPort").
+func BrowseLAN(addr string) (*LANBrowser, error) {
+	conn, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	b := &LANBrowser{conn: conn, games: make(map[string]LANGame)}
+	go b.read()
+	return b, nil
+}
+
+func (b *LANBrowser) read() {
+	buf := make([]byte, maxAnnounceSize)
+	for {
+		n, from, err := b.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var msg Announcement
+		if json.Unmarshal(buf[:n], &msg) != nil || msg.Version != ProtocolVersion || msg.Port <= 0 {
+			continue // not ours, or from an incompatible version
+		}
+		udp, ok := from.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		game := LANGame{Announcement: msg, Addr: net.JoinHostPort(udp.IP.String(), fmt.Sprint(msg.Port)), seen: time.Now()}
+		b.mu.Lock()
+		b.games[game.Addr] = game
+		b.mu.Unlock()
+	}
+}
+
+// LocalAddr is the address the browser is listening on.
+func (b *LANBrowser) LocalAddr() net.Addr {
+	return b.conn.LocalAddr()
+}
+
+// Games lists the games heard from recently, sorted by name.
+func (b *LANBrowser) Games() []LANGame {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	games := make([]LANGame, 0, len(b.games))
+	for addr, game := range b.games {
+		if time.Since(game.seen) > announceExpiry {
+			delete(b.games, addr)
+			continue
+		}
+		games = append(games, game)
+	}
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Name != games[j].Name {
+			return games[i].Name < games[j].Name
+		}
+		return games[i].Addr < games[j].Addr
+	})
+	return games
+}
+
+// Close stops listening.
+func (b *LANBrowser) Close() error {
+	return b.conn.Close()
+}
+
+// RulesSummary describes a game's settings in a line, for game lists.
+func RulesSummary(config types.GameConfig) string {
+	parts := []string{
+		fmt.Sprintf("%dx%d", config.GridWidth, config.GridHeight),
+		fmt.Sprintf("line %d", config.LineLength),
+		fmt.Sprintf("%d players", config.PlayerCount),
+	}
+	if config.BestOf > 1 {
+		parts = append(parts, fmt.Sprintf("best of %d", config.BestOf))
+	}
+	rules := []struct {
+		on   bool
+		name string
+	}{
+		{config.CornerBonus, "corner bonus"},
+		{config.SolitaireRule, "solitaire"},
+		{config.BombCounter, "bombs"},
+		{config.OverflowRule, "overflow"},
+		{config.EnableAlliances, "alliances"},
+	}
+	for _, rule := range rules {
+		if rule.on {
+			parts = append(parts, rule.name)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
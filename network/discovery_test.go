@@ -0,0 +1,47 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+func TestAnnounceOverLoopback(t *testing.T) {
+	browser, err := BrowseLAN("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer browser.Close()
+
+	config := types.GameConfig{
+		GridWidth: 7, GridHeight: 6, LineLength: 4, PlayerCount: 2, BestOf: 1,
+		PlayerTypes: []int{types.HumanPlayer, types.RemotePlayer},
+	}
+	server := NewServer(ui.NewGameFromConfig(config))
+	defer server.Close()
+	announcer, err := server.Announce("Test Game", DefaultPort, browser.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer announcer.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		games := browser.Games()
+		if len(games) == 0 {
+			time.Sleep(20 * time.Millisecond)
+			continue
+		}
+		game := games[0]
+		if game.Name != "Test Game" || game.Addr != "127.0.0.1:4747" || game.FreeSeats != 1 {
+			t.Fatalf("heard %+v, want Test Game at 127.0.0.1:4747 with 1 free seat", game)
+		}
+		if game.Config.GridWidth != 7 || game.Config.PlayerCount != 2 {
+			t.Fatalf("heard settings %+v, want the hosted game's", game.Config)
+		}
+		return
+	}
+	t.Fatal("no game heard within 3 seconds")
+}
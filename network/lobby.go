@@ -0,0 +1,488 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// Lobby lets players create rooms, pick seats and start games together. Each
+// room gets its own Server once everyone sitting in it is ready. Rooms carry
+// on when a player drops out and are removed once nobody is left in them.
+type Lobby struct {
+	mu       sync.Mutex
+	rooms    map[int]*room
+	nextID   int
+	members  map[*member]bool
+	listener net.Listener
+
+	spectatorDelay time.Duration
+	gracePeriod    time.Duration
+}
+
+type room struct {
+	id       int
+	name     string
+	config   types.GameConfig
+	seats    []*member // nil while the seat is free
+	ready    map[*member]bool
+	watchers map[*member]bool
+	server   *Server // nil until the game starts
+}
+
+// member is one connection to the lobby.
+type member struct {
+	conn    *Conn
+	name    string
+	room    *room
+	seat    int
+	player  *client    // set once the room's game starts
+	watcher *spectator // set when watching rather than playing
+}
+
+// NewLobby creates an empty lobby.
+func NewLobby() *Lobby {
+	return &Lobby{
+		rooms:          make(map[int]*room),
+		members:        make(map[*member]bool),
+		spectatorDelay: DefaultSpectatorDelay,
+		gracePeriod:    DefaultGracePeriod,
+	}
+}
+
+// SetSpectatorDelay sets how far behind the live game spectators of rooms
+// started from now on are.
+func (l *Lobby) SetSpectatorDelay(delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.spectatorDelay = delay
+}
+
+// SetGracePeriod sets how long players who drop out of rooms started from
+// now on have to reconnect.
+func (l *Lobby) SetGracePeriod(grace time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.gracePeriod = grace
+}
+
+// Serve accepts connections until the listener is closed.
+func (l *Lobby) Serve(listener net.Listener) error {
+	l.mu.Lock()
+	l.listener = listener
+	l.mu.Unlock()
+	for {
+		c, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go l.ServeConn(c)
+	}
+}
+
+// Close stops accepting connections, ends every game and disconnects everyone.
+func (l *Lobby) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.listener != nil {
+		l.listener.Close()
+	}
+	for _, r := range l.rooms {
+		if r.server != nil {
+			r.server.Close()
+		}
+	}
+	for m := range l.members {
+		m.conn.Close()
+	}
+}
+
+// ServeConn talks to one player until they disconnect.
+func (l *Lobby) ServeConn(c net.Conn) {
+	conn := NewConn(c)
+	defer conn.Close()
+
+	hello, ok := receiveHello(conn)
+	if !ok {
+		return
+	}
+
+	m := &member{conn: conn, name: hello.Name, seat: -1}
+	l.mu.Lock()
+	l.members[m] = true
+	var err error
+	if hello.Token == "" || !l.resume(m, hello.Token) {
+		err = conn.Send(Message{Type: MsgRooms, Rooms: l.roomList()})
+	}
+	l.mu.Unlock()
+	defer l.disconnect(m)
+	if err != nil {
+		return
+	}
+
+	for {
+		msg, err := conn.Receive()
+		if err != nil {
+			return
+		}
+		l.handle(m, msg)
+	}
+}
+
+func (l *Lobby) disconnect(m *member) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.leave(m, true)
+	delete(l.members, m)
+}
+
+// resume puts a player who dropped out of a game back in their seat.
+// Must be called with l.mu held.
+func (l *Lobby) resume(m *member, token string) bool {
+	for _, r := range l.rooms {
+		if r.server == nil || !r.server.hasSession(token) {
+			continue
+		}
+		player := &client{conn: m.conn, name: m.name}
+		if err := r.server.resume(player, token); err != nil {
+			m.conn.Send(Message{Type: MsgError, Error: err.Error()})
+			return false
+		}
+		m.room, m.seat, m.player = r, player.seats[0], player
+		r.seats[m.seat] = m
+		l.notify(r)
+		return true
+	}
+	return false
+}
+
+func (l *Lobby) handle(m *member, msg Message) {
+	l.mu.Lock()
+	if (msg.Type == MsgMove || msg.Type == MsgChat) && m.player != nil {
+		// Game messages go straight to the room's server
+		server, player := m.room.server, m.player
+		l.mu.Unlock()
+		server.handle(player, msg)
+		return
+	}
+	defer l.mu.Unlock()
+
+	var err error
+	switch msg.Type {
+	case MsgListRooms:
+		err = m.conn.Send(Message{Type: MsgRooms, Rooms: l.roomList()})
+	case MsgCreateRoom:
+		err = l.createRoom(m, msg.Room)
+	case MsgJoinRoom:
+		seat := -1
+		if len(msg.Seats) > 0 {
+			seat = msg.Seats[0]
+		}
+		if msg.Watch {
+			err = l.watchRoom(m, msg.RoomID)
+		} else {
+			err = l.joinRoom(m, msg.RoomID, seat)
+		}
+	case MsgLeaveRoom:
+		l.leave(m, false)
+	case MsgReady:
+		err = l.setReady(m, msg.Ready)
+	default:
+		err = fmt.Errorf("unexpected %q message", msg.Type)
+	}
+	if err != nil {
+		m.conn.Send(Message{Type: MsgError, Error: err.Error()})
+	}
+}
+
+func (l *Lobby) createRoom(m *member, info *RoomInfo) error {
+	if info == nil {
+		return fmt.Errorf("create_room message without a room")
+	}
+	if err := checkRemoteConfig(info.Config); err != nil {
+		return err
+	}
+	l.nextID++
+	r := &room{
+		id:       l.nextID,
+		name:     info.Name,
+		config:   info.Config,
+		seats:    make([]*member, info.Config.PlayerCount),
+		ready:    make(map[*member]bool),
+		watchers: make(map[*member]bool),
+	}
+	if r.name == "" {
+		r.name = fmt.Sprintf("Room %d", r.id)
+	}
+	l.rooms[r.id] = r
+	if err := l.joinRoom(m, r.id, -1); err != nil {
+		// Every seat is an AI, so there is nobody to play or watch
+		delete(l.rooms, r.id)
+		return err
+	}
+	return nil
+}
+
+// checkRemoteConfig checks settings sent by a lobby client. Besides the
+// usual checks, seats that would use the host's own resources are turned
+// down: an external engine starts a program on the host, and the adaptive
+// AI keeps its records in the host's files.
+func checkRemoteConfig(config types.GameConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	for i, playerType := range config.PlayerTypes[:config.PlayerCount] {
+		switch playerType {
+		case types.ExternalEngine:
+			return fmt.Errorf("player %d can't be an external engine in a lobby game", i+1)
+		case types.AdaptiveAI:
+			return fmt.Errorf("player %d can't be the adaptive AI in a lobby game", i+1)
+		}
+	}
+	return nil
+}
+
+// joinRoom sits a member in a room, in the given seat or the first free one
+// when seat is -1. Rooms that have started can still be joined if a seat
+// is free.
+func (l *Lobby) joinRoom(m *member, id, seat int) error {
+	r := l.rooms[id]
+	if r == nil {
+		return fmt.Errorf("no room %d", id)
+	}
+	if m.room == r {
+		return fmt.Errorf("you are already in room %q", r.name)
+	}
+	if seat == -1 {
+		for i := range r.seats {
+			if l.seatOpen(r, i) {
+				seat = i
+				break
+			}
+		}
+		if seat == -1 {
+			return fmt.Errorf("room %q is full", r.name)
+		}
+	} else if seat < 0 || seat >= len(r.seats) || !l.seatOpen(r, seat) {
+		return fmt.Errorf("seat %d in room %q is not free", seat+1, r.name)
+	}
+
+	l.leave(m, false)
+	m.room, m.seat = r, seat
+	r.seats[seat] = m
+	if r.server != nil {
+		m.player = &client{conn: m.conn, name: m.name, seats: []int{seat}}
+		if err := r.server.join(m.player); err != nil {
+			return err
+		}
+	}
+	l.notify(r)
+	return nil
+}
+
+// watchRoom lets a member watch a room's game without taking a seat.
+func (l *Lobby) watchRoom(m *member, id int) error {
+	r := l.rooms[id]
+	if r == nil {
+		return fmt.Errorf("no room %d", id)
+	}
+	if r.server == nil {
+		return fmt.Errorf("room %q hasn't started yet", r.name)
+	}
+	if m.room == r {
+		return fmt.Errorf("you are already in room %q", r.name)
+	}
+
+	l.leave(m, false)
+	sp, err := r.server.addSpectator(m.conn)
+	if err != nil {
+		return err
+	}
+	m.room, m.watcher = r, sp
+	r.watchers[m] = true
+	l.notify(r)
+	return nil
+}
+
+// seatOpen reports whether a person could sit in the seat.
+func (l *Lobby) seatOpen(r *room, seat int) bool {
+	if r.seats[seat] != nil {
+		return false
+	}
+	if r.server != nil {
+		return r.server.seatFree(seat)
+	}
+	return !types.IsAI(r.config.PlayerTypes[seat])
+}
+
+// leave takes a member out of their room, removing the room if it is now
+// empty. Players whose connection dropped keep their seat in the game for
+// the grace period.
+func (l *Lobby) leave(m *member, dropped bool) {
+	r := m.room
+	if r == nil {
+		return
+	}
+	if m.watcher != nil {
+		r.server.removeSpectator(m.watcher)
+		delete(r.watchers, m)
+		m.room, m.watcher = nil, nil
+		l.notify(r)
+		return
+	}
+	r.seats[m.seat] = nil
+	delete(r.ready, m)
+	if m.player != nil && dropped {
+		r.server.drop(m.player)
+	} else if m.player != nil {
+		r.server.release(m.player)
+	}
+	m.room, m.seat, m.player = nil, -1, nil
+	l.closeIfEmpty(r)
+}
+
+// closeIfEmpty removes a room once nobody is sitting in it or coming back
+// to it. Must be called with l.mu held.
+func (l *Lobby) closeIfEmpty(r *room) {
+	if l.rooms[r.id] != r {
+		return // already gone
+	}
+	for _, seated := range r.seats {
+		if seated != nil {
+			l.notify(r)
+			return
+		}
+	}
+	if r.server != nil && r.server.awaiting() {
+		l.notify(r)
+		return
+	}
+	for w := range r.watchers {
+		r.server.removeSpectator(w.watcher)
+		w.room, w.watcher = nil, nil
+	}
+	if r.server != nil {
+		r.server.Close()
+	}
+	delete(l.rooms, r.id)
+	l.notify(nil)
+}
+
+func (l *Lobby) setReady(m *member, ready bool) error {
+	r := m.room
+	if r == nil {
+		return fmt.Errorf("you are not in a room")
+	}
+	if r.server != nil {
+		return nil // already playing
+	}
+	if ready {
+		r.ready[m] = true
+	} else {
+		delete(r.ready, m)
+	}
+	if l.canStart(r) {
+		l.start(r)
+	}
+	l.notify(r)
+	return nil
+}
+
+// canStart reports whether everyone seated is ready and every seat that
+// needs a person has one, or can be given to the AI.
+func (l *Lobby) canStart(r *room) bool {
+	seated := 0
+	for seat, m := range r.seats {
+		if m == nil {
+			if !types.IsAI(r.config.PlayerTypes[seat]) && !r.config.AIForMissing {
+				return false
+			}
+			continue
+		}
+		if !r.ready[m] {
+			return false
+		}
+		seated++
+	}
+	return seated > 0
+}
+
+// start creates the room's game. Seated players become remote players and
+// empty seats are filled by the AI.
+func (l *Lobby) start(r *room) {
+	config := r.config
+	config.PlayerTypes = append([]int(nil), r.config.PlayerTypes...)
+	for seat, m := range r.seats {
+		if types.IsAI(config.PlayerTypes[seat]) {
+			continue
+		}
+		if m != nil {
+			config.PlayerTypes[seat] = types.RemotePlayer
+		} else {
+			config.PlayerTypes[seat] = types.MediumAI
+		}
+	}
+
+	r.server = NewServer(ui.NewGameFromConfig(config))
+	r.server.SetSpectatorDelay(l.spectatorDelay)
+	r.server.SetGracePeriod(l.gracePeriod)
+	r.server.expired = func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.closeIfEmpty(r)
+	}
+	for seat, m := range r.seats {
+		if m == nil {
+			continue
+		}
+		m.player = &client{conn: m.conn, name: m.name, seats: []int{seat}}
+		if err := r.server.join(m.player); err != nil {
+			m.conn.Close() // their read loop will tidy up
+		}
+	}
+}
+
+// notify tells the room's members about a change to it, and everyone not in
+// a room about the new list of rooms. Must be called with l.mu held.
+func (l *Lobby) notify(r *room) {
+	if r != nil {
+		info := r.info()
+		for _, m := range r.seats {
+			if m != nil {
+				m.conn.Send(Message{Type: MsgRoom, Room: &info})
+			}
+		}
+	}
+	rooms := l.roomList()
+	for m := range l.members {
+		if m.room == nil {
+			m.conn.Send(Message{Type: MsgRooms, Rooms: rooms})
+		}
+	}
+}
+
+func (l *Lobby) roomList() []RoomInfo {
+	rooms := make([]RoomInfo, 0, len(l.rooms))
+	for _, r := range l.rooms {
+		rooms = append(rooms, r.info())
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+	return rooms
+}
+
+func (r *room) info() RoomInfo {
+	info := RoomInfo{ID: r.id, Name: r.name, Config: r.config, Started: r.server != nil, Watchers: len(r.watchers)}
+	for seat, m := range r.seats {
+		s := SeatInfo{AI: types.IsAI(r.config.PlayerTypes[seat])}
+		if m != nil {
+			s.Player, s.Taken, s.Ready = m.name, true, r.ready[m]
+		}
+		info.Seats = append(info.Seats, s)
+	}
+	return info
+}
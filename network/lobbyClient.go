@@ -0,0 +1,231 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// LobbyEvents are called from the LobbyClient's own goroutine as messages
+// arrive. Any of them may be nil.
+type LobbyEvents struct {
+	Rooms     func(rooms []RoomInfo) // the list of rooms changed
+	Room      func(room RoomInfo)    // the room we are in changed
+	GameStart func(game *Client)     // our room's game started, or we joined one in progress
+	Error     func(message string)   // a request was refused or the connection dropped
+}
+
+// LobbyClient is a player's connection to a lobby.
+type LobbyClient struct {
+	conn   *Conn
+	events LobbyEvents
+	mu     sync.Mutex
+	game   *Client // the game being played, if any
+	closed bool
+
+	// For getting back into a game after a dropped connection
+	name   string
+	redial func() (net.Conn, error)
+}
+
+// DialLobby connects to the lobby at addr (host:port). If the connection
+// drops during a game the client dials again and reclaims its seat.
+func DialLobby(addr, name string, events LobbyEvents) (*LobbyClient, error) {
+	redial := func() (net.Conn, error) { return net.DialTimeout("tcp", addr, dialTimeout) }
+	c, err := redial()
+	if err != nil {
+		return nil, err
+	}
+	lc, err := NewLobbyClient(c, name, events)
+	if err != nil {
+		return nil, err
+	}
+	lc.mu.Lock()
+	lc.redial = redial
+	lc.mu.Unlock()
+	return lc, nil
+}
+
+// NewLobbyClient says hello to a lobby over an existing connection.
+func NewLobbyClient(c net.Conn, name string, events LobbyEvents) (*LobbyClient, error) {
+	conn := NewConn(c)
+	if err := conn.Send(Message{Type: MsgHello, Version: ProtocolVersion, Name: name}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	first, err := conn.Receive()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if first.Type == MsgError {
+		conn.Close()
+		return nil, errors.New(first.Error)
+	}
+
+	lc := &LobbyClient{conn: conn, events: events, name: name}
+	go func() {
+		lc.handle(first)
+		lc.read()
+	}()
+	return lc, nil
+}
+
+func (lc *LobbyClient) read() {
+	for {
+		msg, err := lc.currentConn().Receive()
+		if err != nil {
+			if lc.reconnect() {
+				continue
+			}
+			lc.endGame()
+			if lc.events.Error != nil {
+				lc.events.Error("Disconnected from the lobby!")
+			}
+			return
+		}
+		lc.handle(msg)
+	}
+}
+
+func (lc *LobbyClient) currentConn() *Conn {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.conn
+}
+
+// reconnect gets back into the lobby after the connection drops in the
+// middle of a game, reclaiming our seat with the game's token.
+func (lc *LobbyClient) reconnect() bool {
+	lc.mu.Lock()
+	game, redial, closed := lc.game, lc.redial, lc.closed
+	lc.mu.Unlock()
+	if game == nil || redial == nil || closed || game.Token() == "" {
+		return false
+	}
+
+	game.notice("Connection lost, reconnecting...")
+	hello := Message{Type: MsgHello, Version: ProtocolVersion, Name: lc.name, Token: game.Token()}
+	for deadline := time.Now().Add(reconnectTimeout); time.Now().Before(deadline); time.Sleep(reconnectInterval) {
+		nc, err := redial()
+		if err != nil {
+			continue
+		}
+		conn := NewConn(nc)
+		if err := conn.Send(hello); err != nil {
+			conn.Close()
+			continue
+		}
+		first, err := conn.Receive()
+		if err != nil {
+			conn.Close()
+			continue
+		}
+
+		lc.mu.Lock()
+		lc.conn = conn
+		lc.mu.Unlock()
+		if first.Type == MsgWelcome && first.State != nil {
+			game.resumed(conn, first)
+			return true
+		}
+		// Back in the lobby, but the game has gone
+		lc.endGame()
+		lc.handle(first)
+		return true
+	}
+	return false
+}
+
+func (lc *LobbyClient) handle(msg Message) {
+	switch msg.Type {
+	case MsgRooms:
+		if lc.events.Rooms != nil {
+			lc.events.Rooms(msg.Rooms)
+		}
+	case MsgRoom:
+		if msg.Room != nil && lc.events.Room != nil {
+			lc.events.Room(*msg.Room)
+		}
+	case MsgWelcome:
+		if msg.Config == nil || msg.State == nil {
+			return
+		}
+		game := newClient(lc.conn, msg)
+		game.leave = lc.LeaveRoom
+		lc.mu.Lock()
+		lc.game = game
+		lc.mu.Unlock()
+		if lc.events.GameStart != nil {
+			lc.events.GameStart(game)
+		}
+	case MsgState, MsgDelta, MsgChat, MsgError:
+		// Held while passing the message on so endGame can't close the
+		// game's channels underneath it
+		lc.mu.Lock()
+		game := lc.game
+		if game != nil {
+			game.handle(msg)
+		}
+		lc.mu.Unlock()
+		if game == nil && msg.Type == MsgError && lc.events.Error != nil {
+			lc.events.Error(msg.Error)
+		}
+	}
+}
+
+// endGame stops passing messages to the current game, closing its updates.
+func (lc *LobbyClient) endGame() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.game != nil {
+		close(lc.game.updates)
+		lc.game = nil
+	}
+}
+
+// ListRooms asks for the list of rooms again.
+func (lc *LobbyClient) ListRooms() error {
+	return lc.currentConn().Send(Message{Type: MsgListRooms})
+}
+
+// CreateRoom opens a new room with the given settings and sits us in it.
+func (lc *LobbyClient) CreateRoom(name string, config types.GameConfig) error {
+	return lc.currentConn().Send(Message{Type: MsgCreateRoom, Room: &RoomInfo{Name: name, Config: config}})
+}
+
+// JoinRoom sits us in a room, in the given seat or any free one if seat is -1.
+func (lc *LobbyClient) JoinRoom(id, seat int) error {
+	msg := Message{Type: MsgJoinRoom, RoomID: id}
+	if seat >= 0 {
+		msg.Seats = []int{seat}
+	}
+	return lc.currentConn().Send(msg)
+}
+
+// WatchRoom watches a room's game without taking a seat.
+func (lc *LobbyClient) WatchRoom(id int) error {
+	return lc.currentConn().Send(Message{Type: MsgJoinRoom, RoomID: id, Watch: true})
+}
+
+// SetReady says whether we are ready for the room's game to start.
+func (lc *LobbyClient) SetReady(ready bool) error {
+	return lc.currentConn().Send(Message{Type: MsgReady, Ready: ready})
+}
+
+// LeaveRoom gives up our seat, leaving the game if it has started.
+func (lc *LobbyClient) LeaveRoom() error {
+	lc.endGame()
+	return lc.currentConn().Send(Message{Type: MsgLeaveRoom})
+}
+
+// Close disconnects from the lobby.
+func (lc *LobbyClient) Close() error {
+	lc.mu.Lock()
+	lc.closed = true
+	lc.mu.Unlock()
+	return lc.currentConn().Close()
+}
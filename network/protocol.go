@@ -0,0 +1,141 @@
+package network
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// ProtocolVersion is sent in every hello. Bump it whenever a message changes
+// in a way older builds can't read.
+const ProtocolVersion = 1
+
+// Default TCP ports for a hosted game and for a lobby.
+const (
+	DefaultPort      = 4747
+	DefaultLobbyPort = 4748
+)
+
+// Message types. Every message is one line of JSON.
+const (
+	MsgHello   = "hello"   // client -> server, first message on a connection
+	MsgWelcome = "welcome" // server -> client, seats given plus the game settings
+	MsgMove    = "move"    // client -> server
+	MsgState   = "state"   // server -> client, sent after every change
+	MsgError   = "error"   // server -> client, e.g. an illegal move
+	MsgDelta   = "delta"   // server -> spectator, State without a grid plus the changed Cells
+	MsgChat    = "chat"    // both ways; the server fills in who said it and when
+
+	MsgListRooms  = "list_rooms"  // client -> lobby
+	MsgRooms      = "rooms"       // lobby -> client, every room
+	MsgCreateRoom = "create_room" // client -> lobby, Room holds the name and settings
+	MsgJoinRoom   = "join_room"   // client -> lobby, RoomID plus an optional seat in Seats
+	MsgLeaveRoom  = "leave_room"  // client -> lobby
+	MsgReady      = "ready"       // client -> lobby, Ready says whether to start
+	MsgRoom       = "room"        // lobby -> client, the room you are in changed
+)
+
+const writeTimeout = 5 * time.Second
+
+// Message is the single envelope used for everything sent over a connection.
+// Only the fields relevant to Type are filled in.
+type Message struct {
+	Type    string            `json:"type"`
+	Version int               `json:"version,omitempty"`
+	Name    string            `json:"name,omitempty"`
+	Watch   bool              `json:"watch,omitempty"` // hello/join_room as a spectator
+	Token   string            `json:"token,omitempty"` // welcome gives one, hello sends it back to reclaim seats
+	Seats   []int             `json:"seats,omitempty"`
+	Config  *types.GameConfig `json:"config,omitempty"`
+	Move    *types.Move       `json:"move,omitempty"`
+	State   *types.GameState  `json:"state,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	RoomID  int               `json:"roomId,omitempty"`
+	Ready   bool              `json:"ready,omitempty"`
+	Room    *RoomInfo         `json:"room,omitempty"`
+	Rooms   []RoomInfo        `json:"rooms,omitempty"`
+	Cells   []CellChange      `json:"cells,omitempty"`
+
+	Chat        *types.ChatMessage  `json:"chat,omitempty"`
+	ChatHistory []types.ChatMessage `json:"chatHistory,omitempty"` // welcome, everything said so far that you may read
+}
+
+// CellChange is one cell of the grid that changed since the last update.
+type CellChange struct {
+	Row    int `json:"r"`
+	Column int `json:"c"`
+	Player int `json:"p"`
+}
+
+// gridChanges lists the cells that differ between two grids of the same size.
+func gridChanges(from, to [][]int) []CellChange {
+	var changes []CellChange
+	for row := range to {
+		for col := range to[row] {
+			if from[row][col] != to[row][col] {
+				changes = append(changes, CellChange{Row: row, Column: col, Player: to[row][col]})
+			}
+		}
+	}
+	return changes
+}
+
+// RoomInfo describes a lobby room and who is sitting where.
+type RoomInfo struct {
+	ID       int              `json:"id"`
+	Name     string           `json:"name"`
+	Config   types.GameConfig `json:"config"`
+	Seats    []SeatInfo       `json:"seats"`
+	Started  bool             `json:"started,omitempty"`
+	Watchers int              `json:"watchers,omitempty"`
+}
+
+// SeatInfo is one seat in a room.
+type SeatInfo struct {
+	Player string `json:"player,omitempty"` // name of whoever is sitting here
+	Taken  bool   `json:"taken,omitempty"`
+	AI     bool   `json:"ai,omitempty"` // always played by the AI
+	Ready  bool   `json:"ready,omitempty"`
+}
+
+// Conn reads and writes newline-delimited JSON messages. Sends are safe to
+// call from several goroutines; Receive should only be called from one.
+type Conn struct {
+	conn net.Conn
+	dec  *json.Decoder
+	enc  *json.Encoder
+	mu   sync.Mutex
+}
+
+// NewConn wraps a connection, which may be TCP or an in-process net.Pipe.
+func NewConn(c net.Conn) *Conn {
+	return &Conn{
+		conn: c,
+		dec:  json.NewDecoder(bufio.NewReader(c)),
+		enc:  json.NewEncoder(c),
+	}
+}
+
+// Send writes one message, giving up if the other end stops reading.
+func (c *Conn) Send(msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.enc.Encode(msg)
+}
+
+// Receive blocks until the next message arrives.
+func (c *Conn) Receive() (Message, error) {
+	var msg Message
+	err := c.dec.Decode(&msg)
+	return msg, err
+}
+
+// Close closes the underlying connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,353 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// Delays so people can follow what the AI and the end of a round did.
+const (
+	aiMoveDelay = 300 * time.Millisecond
+	roundPause  = 3 * time.Second
+)
+
+// Server hosts a game and is the only place moves are applied. Clients send
+// moves, the server checks them against the rules and sends everyone the new
+// state. AI seats are played by the server itself.
+type Server struct {
+	mu       sync.Mutex
+	game     *ui.Game
+	clients  map[*client]bool
+	seats    map[int]*client // seat -> client playing it
+	listener net.Listener
+	closed   bool
+
+	spectators     map[*spectator]bool
+	spectatorDelay time.Duration
+	history        []timedState // recent states, for spectators who join late
+
+	sessions    map[string]*session // token -> remote player's claim on their seats
+	gracePeriod time.Duration
+	expired     func() // called once a dropped player's grace period runs out
+}
+
+type client struct {
+	conn    *Conn
+	name    string
+	seats   []int
+	session *session // nil for the host's local client
+}
+
+// NewServer hosts the given game. Seats with the RemotePlayer type are handed
+// out to clients that connect over the network; HumanPlayer seats belong to
+// the host's local client.
+func NewServer(game *ui.Game) *Server {
+	// Seats can be handed to the AI and back, so don't share the setup screen's slice
+	game.PlayerTypes = append([]int(nil), game.PlayerTypes...)
+	s := &Server{
+		game:           game,
+		clients:        make(map[*client]bool),
+		seats:          make(map[int]*client),
+		spectators:     make(map[*spectator]bool),
+		spectatorDelay: DefaultSpectatorDelay,
+		sessions:       make(map[string]*session),
+		gracePeriod:    DefaultGracePeriod,
+	}
+	s.mu.Lock()
+	s.record(s.state())
+	s.advance()
+	s.mu.Unlock()
+	return s
+}
+
+// Serve accepts connections until the listener is closed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(c)
+	}
+}
+
+// ServeConn talks to one remote player until they disconnect.
+func (s *Server) ServeConn(c net.Conn) {
+	s.serve(NewConn(c), false)
+}
+
+// LocalClient connects the host's own window to the game over an in-process
+// pipe, giving it every HumanPlayer seat.
+func (s *Server) LocalClient(name string) (*Client, error) {
+	serverEnd, clientEnd := net.Pipe()
+	go s.serve(NewConn(serverEnd), true)
+	return NewClient(clientEnd, name)
+}
+
+// Close stops accepting players and disconnects everyone.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	for sp := range s.spectators {
+		sp.conn.Close()
+	}
+	s.game.CloseEngines()
+}
+
+func (s *Server) serve(conn *Conn, local bool) {
+	defer conn.Close()
+
+	hello, ok := receiveHello(conn)
+	if !ok {
+		return
+	}
+	if hello.Watch {
+		s.watch(conn)
+		return
+	}
+
+	c := &client{conn: conn, name: hello.Name}
+	if hello.Token != "" {
+		if err := s.resume(c, hello.Token); err != nil {
+			conn.Send(Message{Type: MsgError, Error: err.Error()})
+			return
+		}
+		defer s.drop(c)
+		s.receive(c)
+		return
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	c.seats = s.claimSeats(c, local)
+	if !local && len(c.seats) == 0 {
+		s.mu.Unlock()
+		conn.Send(Message{Type: MsgError, Error: "no free seats in this game"})
+		return
+	}
+	if !local {
+		s.newSession(c)
+	}
+	err := s.welcome(c)
+	s.mu.Unlock()
+	defer s.drop(c)
+	if err != nil {
+		return
+	}
+	s.receive(c)
+}
+
+// receive handles a welcomed client's messages until it disconnects.
+func (s *Server) receive(c *client) {
+	for {
+		msg, err := c.conn.Receive()
+		if err != nil {
+			return
+		}
+		s.handle(c, msg)
+	}
+}
+
+// receiveHello reads the first message on a connection and checks the other
+// end speaks our protocol version.
+func receiveHello(conn *Conn) (Message, bool) {
+	hello, err := conn.Receive()
+	if err != nil {
+		return hello, false
+	}
+	if hello.Type != MsgHello {
+		conn.Send(Message{Type: MsgError, Error: "expected hello"})
+		return hello, false
+	}
+	if hello.Version != ProtocolVersion {
+		conn.Send(Message{Type: MsgError, Error: fmt.Sprintf("unsupported protocol version %d, host uses %d", hello.Version, ProtocolVersion)})
+		return hello, false
+	}
+	return hello, true
+}
+
+// welcome adds a client whose seats are already claimed and sends it the
+// game so far. Must be called with s.mu held.
+func (s *Server) welcome(c *client) error {
+	s.clients[c] = true
+	config := s.game.Config()
+	state := s.state()
+	welcome := Message{Type: MsgWelcome, Version: ProtocolVersion, Seats: c.seats, Config: &config, State: &state, ChatHistory: s.chatHistory(c.seats)}
+	if c.session != nil {
+		welcome.Token = c.session.token
+	}
+	return c.conn.Send(welcome)
+}
+
+// join adds a player whose seats were picked elsewhere, such as in a lobby room.
+func (s *Server) join(c *client) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, seat := range c.seats {
+		s.seats[seat] = c
+	}
+	s.newSession(c)
+	return s.welcome(c)
+}
+
+// handle deals with one message from a client that has been welcomed.
+func (s *Server) handle(c *client, msg Message) {
+	switch msg.Type {
+	case MsgMove:
+		s.handleMove(c, msg.Move)
+	case MsgChat:
+		s.handleChat(c, msg.Chat)
+	default:
+		c.conn.Send(Message{Type: MsgError, Error: fmt.Sprintf("unexpected %q message", msg.Type)})
+	}
+}
+
+// claimSeats gives a client the seats it may play. Remote clients get the
+// first free RemotePlayer seat; the local client gets every HumanPlayer seat.
+func (s *Server) claimSeats(c *client, local bool) []int {
+	var seats []int
+	for seat, playerType := range s.game.PlayerTypes[:s.game.Players] {
+		if s.seats[seat] != nil || s.held(seat) {
+			continue
+		}
+		if local && playerType == types.HumanPlayer || !local && playerType == types.RemotePlayer {
+			s.seats[seat] = c
+			seats = append(seats, seat)
+			if !local {
+				break
+			}
+		}
+	}
+	return seats
+}
+
+// drop forgets a client and frees its seats for someone else.
+func (s *Server) drop(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, c)
+	for _, seat := range c.seats {
+		if s.seats[seat] == c {
+			delete(s.seats, seat)
+		}
+	}
+	if c.session != nil && c.session.client == c {
+		s.disconnected(c.session)
+	}
+}
+
+func (s *Server) handleMove(c *client, move *types.Move) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if move == nil {
+		c.conn.Send(Message{Type: MsgError, Error: "move message without a move"})
+		return
+	}
+	if s.seats[s.game.CurrentTurn] != c || s.game.RoundOver {
+		c.conn.Send(Message{Type: MsgError, Error: "It's not your turn!"})
+		return
+	}
+	if _, err := s.game.PlayMove(*move); err != nil {
+		c.conn.Send(Message{Type: MsgError, Error: err.Error()})
+		return
+	}
+	s.broadcast()
+	s.advance()
+}
+
+// advance schedules whatever happens next without waiting for a player:
+// an AI move, or the start of the next round. Must be called with s.mu held.
+func (s *Server) advance() {
+	game := s.game
+	if game.RoundOver {
+		if game.SeriesOver() {
+			return
+		}
+		time.AfterFunc(roundPause, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.game != game || s.closed {
+				return
+			}
+			s.game = game.NextRound()
+			s.broadcast()
+			s.advance()
+		})
+		return
+	}
+
+	if !types.IsAI(game.PlayerTypes[game.CurrentTurn]) {
+		return
+	}
+	moves := len(game.Moves)
+	time.AfterFunc(aiMoveDelay, func() {
+		// The AI thinks on a copy, so players can still chat and join
+		// while it does
+		s.mu.Lock()
+		if s.game != game || len(game.Moves) != moves || s.closed {
+			s.mu.Unlock()
+			return
+		}
+		playerType := game.PlayerTypes[game.CurrentTurn]
+		if !types.IsAI(playerType) {
+			s.mu.Unlock()
+			return
+		}
+		thinking := game.Copy()
+		s.mu.Unlock()
+		move := thinking.AIMove(playerType)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.game != game || len(game.Moves) != moves || s.closed {
+			return
+		}
+		// A player may have taken their seat back from the AI meanwhile
+		if !types.IsAI(game.PlayerTypes[game.CurrentTurn]) {
+			return
+		}
+		if _, err := game.PlayMove(move); err != nil {
+			fmt.Println("AI move rejected:", err)
+			return
+		}
+		s.broadcast()
+		s.advance()
+	})
+}
+
+// state is the game's state plus what only the server knows. Must be called
+// with s.mu held.
+func (s *Server) state() types.GameState {
+	state := s.game.State()
+	state.Spectators = len(s.spectators)
+	return state
+}
+
+// broadcast sends the current state to every client, and queues it for
+// spectators. Must be called with s.mu held.
+func (s *Server) broadcast() {
+	state := s.state()
+	for c := range s.clients {
+		c.conn.Send(Message{Type: MsgState, State: &state})
+	}
+	s.record(state)
+}
@@ -0,0 +1,153 @@
+package network
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// testServer hosts a two-player game with both seats taken over the network.
+func testServer(t *testing.T) *Server {
+	t.Helper()
+	config := types.GameConfig{
+		GridWidth: 7, GridHeight: 6, LineLength: 4, PlayerCount: 2, BestOf: 1,
+		PlayerTypes: []int{types.RemotePlayer, types.RemotePlayer},
+	}
+	server := NewServer(ui.NewGameFromConfig(config))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// pipeClient joins the server over an in-process pipe.
+func pipeClient(t *testing.T, server *Server, name string) *Client {
+	t.Helper()
+	serverEnd, clientEnd := net.Pipe()
+	go server.ServeConn(serverEnd)
+	client, err := NewClient(clientEnd, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// waitState waits for an update that passes check.
+func waitState(t *testing.T, client *Client, check func(types.GameState) bool) types.GameState {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case state := <-client.Updates():
+			if check(state) {
+				return state
+			}
+		case <-timeout:
+			t.Fatal("no matching state within 2 seconds")
+		}
+	}
+}
+
+// waitNotice waits for the next message from the host.
+func waitNotice(t *testing.T, client *Client) string {
+	t.Helper()
+	select {
+	case notice := <-client.Notices():
+		return notice
+	case <-time.After(2 * time.Second):
+		t.Fatal("no notice within 2 seconds")
+		return ""
+	}
+}
+
+func TestHelloWrongVersion(t *testing.T) {
+	server := testServer(t)
+	serverEnd, clientEnd := net.Pipe()
+	go server.ServeConn(serverEnd)
+	conn := NewConn(clientEnd)
+	defer conn.Close()
+
+	if err := conn.Send(Message{Type: MsgHello, Version: ProtocolVersion + 1, Name: "Future"}); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != MsgError || !strings.Contains(reply.Error, "protocol version") {
+		t.Fatalf("got %+v, want a protocol version error", reply)
+	}
+}
+
+func TestIllegalMoveRefused(t *testing.T) {
+	server := testServer(t)
+	first := pipeClient(t, server, "First")
+	second := pipeClient(t, server, "Second")
+
+	if err := first.SendMove(types.Move{Column: 99}); err != nil {
+		t.Fatal(err)
+	}
+	if notice := waitNotice(t, first); notice == "" {
+		t.Fatal("an off-board move was not refused")
+	}
+	if err := second.SendMove(types.Move{Column: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if notice := waitNotice(t, second); notice != "It's not your turn!" {
+		t.Fatalf("a move out of turn got %q", notice)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.game.Moves) != 0 {
+		t.Fatalf("the host played %d refused moves", len(server.game.Moves))
+	}
+}
+
+func TestLegalMoveBroadcast(t *testing.T) {
+	server := testServer(t)
+	first := pipeClient(t, server, "First")
+	second := pipeClient(t, server, "Second")
+	if seats := first.Seats(); len(seats) != 1 || seats[0] != 0 {
+		t.Fatalf("first client got seats %v, want [0]", seats)
+	}
+
+	if err := first.SendMove(types.Move{Column: 3}); err != nil {
+		t.Fatal(err)
+	}
+	played := func(state types.GameState) bool { return state.Grid[5][3] == 0 }
+	for _, client := range []*Client{first, second} {
+		state := waitState(t, client, played)
+		if state.CurrentTurn != 1 || state.LastMove == nil || state.LastMove.Column != 3 {
+			t.Fatalf("got turn %d and last move %+v, want player 2 to move after column 4", state.CurrentTurn, state.LastMove)
+		}
+	}
+}
+
+func TestAIReplies(t *testing.T) {
+	config := types.GameConfig{
+		GridWidth: 7, GridHeight: 6, LineLength: 4, PlayerCount: 2, BestOf: 1,
+		PlayerTypes: []int{types.RemotePlayer, types.MediumAI},
+	}
+	server := NewServer(ui.NewGameFromConfig(config))
+	t.Cleanup(server.Close)
+	person := pipeClient(t, server, "Person")
+
+	if err := person.SendMove(types.Move{Column: 3}); err != nil {
+		t.Fatal(err)
+	}
+	state := waitState(t, person, func(state types.GameState) bool {
+		return state.LastMove != nil && state.LastMove.Player == 1
+	})
+	if state.CurrentTurn != 0 {
+		t.Fatalf("got turn %d after the AI moved, want player 1 to move", state.CurrentTurn)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.game.Moves) != 2 {
+		t.Fatalf("the host has %d moves, want the person's and the AI's", len(server.game.Moves))
+	}
+}
@@ -0,0 +1,182 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// DefaultGracePeriod is how long a dropped player's seats are kept for them
+// before the AI takes over (or, without AI for missing players, anyone can
+// take them).
+const DefaultGracePeriod = 60 * time.Second
+
+// substituteAI plays for players who haven't come back in time.
+const substituteAI = types.MediumAI
+
+// session is a remote player's claim on their seats. Its token is given to
+// the player in the welcome so they can come back after a dropped connection.
+type session struct {
+	token    string
+	seats    []int
+	client   *client     // nil while disconnected
+	timer    *time.Timer // running while we wait for them to come back
+	replaced map[int]int // seat -> original player type while the AI stands in
+}
+
+// SetGracePeriod sets how long dropped players have to reconnect.
+func (s *Server) SetGracePeriod(grace time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gracePeriod = grace
+}
+
+// newSession gives a client a token for the seats it has just claimed.
+// Must be called with s.mu held.
+func (s *Server) newSession(c *client) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return // no reconnecting, but the game can still be played
+	}
+	c.session = &session{token: hex.EncodeToString(token), seats: c.seats, client: c}
+	s.sessions[c.session.token] = c.session
+}
+
+// held reports whether a seat is being kept for a player who dropped out.
+// Must be called with s.mu held.
+func (s *Server) held(seat int) bool {
+	for _, sess := range s.sessions {
+		if sess.client == nil && sess.timer != nil {
+			for _, held := range sess.seats {
+				if held == seat {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// seatFree reports whether a new remote player could sit in the seat.
+func (s *Server) seatFree(seat int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seats[seat] == nil && !s.held(seat) && s.game.PlayerTypes[seat] == types.RemotePlayer
+}
+
+// awaiting reports whether any dropped player's seats are still being kept.
+func (s *Server) awaiting() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sess := range s.sessions {
+		if sess.client == nil && sess.timer != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSession reports whether a token belongs to a player of this game.
+func (s *Server) hasSession(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[token] != nil
+}
+
+// resume puts a returning player back in their seats, taking them back from
+// the AI if it had stepped in.
+func (s *Server) resume(c *client, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := s.sessions[token]
+	if sess == nil {
+		return errors.New("your seat in this game has gone")
+	}
+	for _, seat := range sess.seats {
+		if taken := s.seats[seat]; taken != nil && taken != sess.client {
+			return errors.New("your seat has been taken by someone else")
+		}
+	}
+
+	if old := sess.client; old != nil {
+		// The old connection hasn't noticed it has dropped yet
+		delete(s.clients, old)
+		old.session = nil
+		old.conn.Close()
+	}
+	if sess.timer != nil {
+		sess.timer.Stop()
+		sess.timer = nil
+	}
+	for seat, playerType := range sess.replaced {
+		s.game.PlayerTypes[seat] = playerType
+	}
+	sess.replaced = nil
+
+	c.seats, c.session, sess.client = sess.seats, sess, c
+	for _, seat := range c.seats {
+		s.seats[seat] = c
+	}
+	if err := s.welcome(c); err != nil {
+		return err
+	}
+	s.broadcast()
+	return nil
+}
+
+// disconnected starts the grace period for a player whose connection dropped.
+// Must be called with s.mu held.
+func (s *Server) disconnected(sess *session) {
+	sess.client = nil
+	if s.closed {
+		return
+	}
+	sess.timer = time.AfterFunc(s.gracePeriod, func() { s.expire(sess) })
+}
+
+// release frees a player's seats straight away, for players who chose to leave.
+func (s *Server) release(c *client) {
+	s.drop(c)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess := c.session; sess != nil {
+		if sess.timer != nil {
+			sess.timer.Stop()
+			sess.timer = nil
+		}
+		delete(s.sessions, sess.token)
+	}
+	if s.game.AIForMissing && !s.closed {
+		for _, seat := range c.seats {
+			s.game.PlayerTypes[seat] = substituteAI
+		}
+		s.advance()
+	}
+}
+
+// expire runs when a dropped player hasn't come back in time.
+func (s *Server) expire(sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess.client != nil || sess.timer == nil || s.closed {
+		return
+	}
+	sess.timer = nil
+	if s.expired != nil {
+		go s.expired()
+	}
+	if !s.game.AIForMissing {
+		return // the seats are now free for anyone
+	}
+
+	sess.replaced = make(map[int]int)
+	for _, seat := range sess.seats {
+		sess.replaced[seat] = s.game.PlayerTypes[seat]
+		s.game.PlayerTypes[seat] = substituteAI
+	}
+	s.advance()
+}
@@ -0,0 +1,156 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// DefaultSpectatorDelay is how far behind the live game spectators are
+// unless the host changes it.
+const DefaultSpectatorDelay = 10 * time.Second
+
+// spectatorQueue is how many updates a spectator can fall behind before
+// being disconnected.
+const spectatorQueue = 256
+
+// spectator is a read-only connection to a hosted game. It sees the game
+// as it was SpectatorDelay ago so it can't be used to coach a player.
+type spectator struct {
+	conn  *Conn
+	queue chan timedState
+}
+
+// timedState is a state together with when it happened. Chat messages for
+// spectators are queued the same way so they arrive in step with the board.
+type timedState struct {
+	at    time.Time
+	state types.GameState
+	chat  *types.ChatMessage
+}
+
+// SetSpectatorDelay sets how far behind the live game spectators are.
+// It only affects updates made after the call.
+func (s *Server) SetSpectatorDelay(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spectatorDelay = delay
+}
+
+// record keeps a state for spectators, both those watching now and those
+// who join within the delay. Must be called with s.mu held.
+func (s *Server) record(state types.GameState) {
+	now := time.Now()
+	s.history = append(s.history, timedState{at: now, state: state})
+
+	// Keep the newest state that is old enough to show, plus everything since
+	cutoff := now.Add(-s.spectatorDelay)
+	keep := 0
+	for i, ts := range s.history {
+		if !ts.at.After(cutoff) {
+			keep = i
+		}
+	}
+	s.history = s.history[keep:]
+
+	for sp := range s.spectators {
+		select {
+		case sp.queue <- s.history[len(s.history)-1]:
+		default:
+			sp.conn.Close() // too far behind; its read loop will remove it
+		}
+	}
+}
+
+// addSpectator starts sending the game to a new spectator, beginning with
+// the full board as it was SpectatorDelay ago.
+func (s *Server) addSpectator(conn *Conn) (*spectator, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.spectatorDelay)
+	first := 0
+	for i, ts := range s.history {
+		if !ts.at.After(cutoff) {
+			first = i
+		}
+	}
+	start := s.history[first].state
+
+	config := s.game.Config()
+	welcome := Message{Type: MsgWelcome, Version: ProtocolVersion, Watch: true, Config: &config, State: &start, ChatHistory: s.chatHistory(nil)}
+	if err := conn.Send(welcome); err != nil {
+		return nil, err
+	}
+
+	sp := &spectator{conn: conn, queue: make(chan timedState, spectatorQueue)}
+	for _, ts := range s.history[first+1:] {
+		select {
+		case sp.queue <- ts:
+		default: // deltas are worked out from what was actually sent, so skipping is safe
+		}
+	}
+	go sp.run(s.spectatorDelay, start)
+	s.spectators[sp] = true
+	s.broadcast() // everyone sees the new spectator count
+	return sp, nil
+}
+
+// removeSpectator stops sending the game to a spectator.
+func (s *Server) removeSpectator(sp *spectator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.spectators[sp] {
+		return
+	}
+	delete(s.spectators, sp)
+	close(sp.queue)
+	if !s.closed {
+		s.broadcast()
+	}
+}
+
+// watch serves a spectator that connected directly rather than through a lobby.
+func (s *Server) watch(conn *Conn) {
+	sp, err := s.addSpectator(conn)
+	if err != nil {
+		return
+	}
+	defer s.removeSpectator(sp)
+	for {
+		msg, err := conn.Receive()
+		if err != nil {
+			return
+		}
+		conn.Send(Message{Type: MsgError, Error: fmt.Sprintf("spectators can't send %q messages", msg.Type)})
+	}
+}
+
+// run sends each queued state once it is old enough. Only the cells that
+// changed are sent, unless the board was replaced by a new round.
+func (sp *spectator) run(delay time.Duration, last types.GameState) {
+	for ts := range sp.queue {
+		time.Sleep(time.Until(ts.at.Add(delay)))
+		if ts.chat != nil {
+			if sp.conn.Send(Message{Type: MsgChat, Chat: ts.chat}) != nil {
+				sp.conn.Close()
+			}
+			continue
+		}
+
+		state := ts.state
+		var err error
+		if state.RoundCount != last.RoundCount || len(state.Grid) != len(last.Grid) {
+			err = sp.conn.Send(Message{Type: MsgState, State: &state})
+		} else {
+			delta := state
+			delta.Grid = nil
+			err = sp.conn.Send(Message{Type: MsgDelta, State: &delta, Cells: gridChanges(last.Grid, state.Grid)})
+		}
+		if err != nil {
+			sp.conn.Close()
+		}
+		last = state
+	}
+}
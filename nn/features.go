@@ -0,0 +1,152 @@
+package nn
+
+import "math"
+
+// FeatureCount is how many numbers Features describes a position with.
+const FeatureCount = 22
+
+// Position is a board seen from one side, with the rules it is played by.
+type Position struct {
+	Grid          [][]int // player numbers from 0, -1 for empty
+	Sides         []int   // each player's side; allies share one
+	Me            int     // the player whose point of view this is
+	ToMove        int     // the player whose turn it is
+	WinLength     int
+	CornerBonus   bool
+	SolitaireRule bool
+	BombCounter   bool
+	OverflowRule  bool
+	BombsUsed     []bool // by player
+}
+
+// Features describes a position with numbers that mean the same on any
+// size of board: how many lines each side is close to finishing, whether
+// those can be finished next move, who holds the middle and the corners,
+// and which rules are on. Counts are log-scaled so big boards don't swamp
+// the network.
+func Features(p Position) []float64 {
+	height, width := len(p.Grid), len(p.Grid[0])
+	mine := p.Sides[p.Me]
+	var (
+		lines     [2][3]float64 // [ours, theirs][missing 1, 2, 3]
+		threats   [2]float64    // lines one short whose gap can be filled now
+		centre    [2]float64
+		corners   [2]float64
+		counters  [2]float64
+		bombsLeft [2]float64
+	)
+	whose := func(player int) int {
+		if p.Sides[player] == mine {
+			return 0
+		}
+		return 1
+	}
+
+	middle := float64(width-1) / 2
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			for _, dir := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+				side, missing, gap, ok := p.line(row, col, dir)
+				if !ok || missing > 3 {
+					continue
+				}
+				lines[whose(side)][missing-1]++
+				if missing == 1 && p.playable(gap[0], gap[1]) {
+					threats[whose(side)]++
+				}
+			}
+
+			player := p.Grid[row][col]
+			if player == -1 {
+				continue
+			}
+			counters[whose(player)]++
+			if middle > 0 {
+				centre[whose(player)] += 1 - math.Abs(float64(col)-middle)/middle
+			}
+			if isCorner(p.Grid, row, col) {
+				corners[whose(player)]++
+			}
+		}
+	}
+	for player, used := range p.BombsUsed {
+		if p.BombCounter && !used {
+			bombsLeft[whose(player)] = 1
+		}
+	}
+
+	cells := float64(width * height)
+	features := make([]float64, 0, FeatureCount)
+	for side := 0; side < 2; side++ {
+		for _, count := range lines[side] {
+			features = append(features, math.Log1p(count))
+		}
+		features = append(features,
+			math.Log1p(threats[side]),
+			centre[side]/math.Max(counters[side], 1),
+			corners[side]/4,
+			counters[side]/cells,
+			bombsLeft[side],
+		)
+	}
+	toMove := 0.0
+	if p.Sides[p.ToMove] == mine {
+		toMove = 1
+	}
+	features = append(features,
+		(counters[0]+counters[1])/cells,
+		toMove,
+		flag(p.CornerBonus), flag(p.SolitaireRule), flag(p.BombCounter), flag(p.OverflowRule),
+	)
+	return features
+}
+
+// line looks at the WinLength cells from a cell in one direction. If only
+// one side has counters there it reports whose, how many more would win,
+// and the last empty cell. Corners count extra with the corner bonus.
+func (p Position) line(row, col int, dir [2]int) (side, missing int, gap [2]int, ok bool) {
+	endRow, endCol := row+dir[0]*(p.WinLength-1), col+dir[1]*(p.WinLength-1)
+	if endRow >= len(p.Grid) || endCol < 0 || endCol >= len(p.Grid[0]) {
+		return 0, 0, gap, false
+	}
+	side, count := -1, 0
+	for k := 0; k < p.WinLength; k++ {
+		r, c := row+dir[0]*k, col+dir[1]*k
+		player := p.Grid[r][c]
+		if player == -1 {
+			gap = [2]int{r, c}
+			continue
+		}
+		if side != -1 && p.Sides[player] != side {
+			return 0, 0, gap, false
+		}
+		side = p.Sides[player]
+		count++
+		if p.CornerBonus && isCorner(p.Grid, r, c) {
+			count++
+			if p.WinLength >= 7 {
+				count++
+			}
+		}
+	}
+	if side == -1 {
+		return 0, 0, gap, false
+	}
+	return side, max(p.WinLength-count, 1), gap, true
+}
+
+// playable reports whether a counter dropped now would land in a cell.
+func (p Position) playable(row, col int) bool {
+	return p.Grid[row][col] == -1 && (row == len(p.Grid)-1 || p.Grid[row+1][col] != -1)
+}
+
+func isCorner(grid [][]int, row, col int) bool {
+	return (row == 0 || row == len(grid)-1) && (col == 0 || col == len(grid[0])-1)
+}
+
+func flag(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
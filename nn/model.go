@@ -0,0 +1,153 @@
+// Package nn is a small neural network for scoring Connectron positions.
+// It has one hidden layer, runs on the CPU in plain Go, and is trained on
+// positions from self-play labelled with how the game turned out.
+package nn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Version is written into every model file. Bump it whenever the format or
+// the features change in a way older builds can't read.
+const Version = 1
+
+// ErrNotModel is returned when reading a file that isn't a model.
+var ErrNotModel = errors.New("not a Connectron model file")
+
+// Model is the network: FeatureCount inputs, a hidden layer of tanh units
+// and one tanh output, the expected result from -1 (lost) to 1 (won).
+type Model struct {
+	Version int         `json:"version"`
+	Inputs  int         `json:"inputs"`
+	Hidden  [][]float64 `json:"hidden"` // a row of input weights per unit, then its bias
+	Output  []float64   `json:"output"` // a weight per hidden unit, then the bias
+
+	// What it was trained on, for reference
+	Config    types.GameConfig `json:"config"`
+	Games     int              `json:"games"`
+	Positions int              `json:"positions"`
+	Loss      float64          `json:"loss"`
+	Trained   time.Time        `json:"trained"`
+}
+
+// Sample is a position's features and how the game turned out for that side.
+type Sample struct {
+	Features []float64
+	Result   float64
+}
+
+// NewModel makes an untrained model with small random weights.
+func NewModel(hidden int, rng *rand.Rand) *Model {
+	m := &Model{Version: Version, Inputs: FeatureCount, Output: make([]float64, hidden+1)}
+	spread := 1 / math.Sqrt(FeatureCount)
+	for i := 0; i < hidden; i++ {
+		unit := make([]float64, FeatureCount+1)
+		for j := range unit[:FeatureCount] {
+			unit[j] = spread * (2*rng.Float64() - 1)
+		}
+		m.Hidden = append(m.Hidden, unit)
+		m.Output[i] = (2*rng.Float64() - 1) / math.Sqrt(float64(hidden))
+	}
+	return m
+}
+
+// Predict scores a position's features from -1 to 1.
+func (m *Model) Predict(features []float64) float64 {
+	out, _ := m.forward(features)
+	return out
+}
+
+// forward also returns the hidden units' values, for training.
+func (m *Model) forward(features []float64) (float64, []float64) {
+	hidden := make([]float64, len(m.Hidden))
+	sum := m.Output[len(m.Hidden)]
+	for i, unit := range m.Hidden {
+		h := unit[m.Inputs]
+		for j, x := range features {
+			h += unit[j] * x
+		}
+		hidden[i] = math.Tanh(h)
+		sum += m.Output[i] * hidden[i]
+	}
+	return math.Tanh(sum), hidden
+}
+
+// Fit trains the model on the samples by stochastic gradient descent on
+// the squared error, shuffling them every epoch. It returns the mean
+// squared error over the last epoch.
+func (m *Model) Fit(samples []Sample, epochs int, rate float64, rng *rand.Rand) float64 {
+	order := rng.Perm(len(samples))
+	loss := 0.0
+	for epoch := 0; epoch < epochs; epoch++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		loss = 0
+		for _, i := range order {
+			loss += m.step(samples[i], rate)
+		}
+		loss /= float64(len(samples))
+	}
+	return loss
+}
+
+// step nudges every weight down the gradient of one sample's error.
+func (m *Model) step(sample Sample, rate float64) float64 {
+	out, hidden := m.forward(sample.Features)
+	diff := out - sample.Result
+	gradOut := diff * (1 - out*out)
+	n := len(m.Hidden)
+	for i, unit := range m.Hidden {
+		gradHidden := gradOut * m.Output[i] * (1 - hidden[i]*hidden[i])
+		for j, x := range sample.Features {
+			unit[j] -= rate * gradHidden * x
+		}
+		unit[m.Inputs] -= rate * gradHidden
+		m.Output[i] -= rate * gradOut * hidden[i]
+	}
+	m.Output[n] -= rate * gradOut
+	return diff * diff
+}
+
+// Read reads a model file.
+func Read(filePath string) (*Model, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotModel, err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("%w: unknown version %d", ErrNotModel, m.Version)
+	}
+	if m.Inputs != FeatureCount || len(m.Output) != len(m.Hidden)+1 {
+		return nil, fmt.Errorf("%w: layers don't fit together", ErrNotModel)
+	}
+	for _, unit := range m.Hidden {
+		if len(unit) != m.Inputs+1 {
+			return nil, fmt.Errorf("%w: layers don't fit together", ErrNotModel)
+		}
+	}
+	return &m, nil
+}
+
+// Write saves a model, replacing any file already there.
+func Write(filePath string, m *Model) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
@@ -0,0 +1,103 @@
+package puzzle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ProgressPath is where everyone's progress through the puzzles is kept.
+var ProgressPath = filepath.Join("files", "puzzle-progress.json")
+
+// ErrNotProgress is returned when reading a file that isn't puzzle progress.
+var ErrNotProgress = errors.New("not a Connectron puzzle progress file")
+
+// Result is how someone has got on with one puzzle.
+type Result struct {
+	Solved   bool      `json:"solved"`
+	Attempts int       `json:"attempts"`
+	Mistakes int       `json:"mistakes"` // moves tried that didn't keep the win, over every attempt
+	SolvedAt time.Time `json:"solvedAt,omitempty"`
+}
+
+// Progress is every profile's results, by profile name and then puzzle id.
+type Progress struct {
+	Version  int                           `json:"version"`
+	Profiles map[string]map[string]*Result `json:"profiles"`
+}
+
+// Result is a profile's result for a puzzle, which is empty if they
+// haven't tried it.
+func (p *Progress) Result(profile, id string) Result {
+	if result := p.Profiles[profile][id]; result != nil {
+		return *result
+	}
+	return Result{}
+}
+
+// Record adds an attempt at a puzzle to a profile's results. Once solved,
+// a puzzle stays solved.
+func (p *Progress) Record(profile, id string, solved bool, mistakes int) {
+	if p.Profiles == nil {
+		p.Profiles = make(map[string]map[string]*Result)
+	}
+	if p.Profiles[profile] == nil {
+		p.Profiles[profile] = make(map[string]*Result)
+	}
+	result := p.Profiles[profile][id]
+	if result == nil {
+		result = &Result{}
+		p.Profiles[profile][id] = result
+	}
+	result.Attempts++
+	result.Mistakes += mistakes
+	if solved && !result.Solved {
+		result.Solved = true
+		result.SolvedAt = time.Now().UTC().Truncate(time.Second)
+	}
+}
+
+// Solved counts the puzzles a profile has solved.
+func (p *Progress) Solved(profile string) int {
+	count := 0
+	for _, result := range p.Profiles[profile] {
+		if result.Solved {
+			count++
+		}
+	}
+	return count
+}
+
+// ReadProgress reads a progress file. A missing file is no progress yet.
+func ReadProgress(filePath string) (*Progress, error) {
+	progress := &Progress{Version: Version, Profiles: make(map[string]map[string]*Result)}
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotProgress, err)
+	}
+	if progress.Version != Version {
+		return nil, fmt.Errorf("%w: unknown version %d", ErrNotProgress, progress.Version)
+	}
+	return progress, nil
+}
+
+// WriteProgress saves everyone's progress, replacing any file already there.
+func WriteProgress(filePath string, progress *Progress) error {
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
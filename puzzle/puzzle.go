@@ -0,0 +1,206 @@
+// Package puzzle reads "win in N" puzzles: a position part way through a
+// game, the rules it is played by, whose move it is and how many of their
+// moves they have to win in. Some come built in; more can be added as
+// files in Dir. Progress through them is kept for each profile.
+package puzzle
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Version is written into every puzzle file. Bump it whenever the format
+// changes in a way older builds can't read.
+const Version = 1
+
+// Limits on puzzles, so checking answers stays quick: the most moves a
+// puzzle may ask for, the widest or tallest board and the most players.
+const (
+	MaxMoves   = 4
+	MaxSize    = 10
+	MaxPlayers = 4
+)
+
+// Dir is where puzzles of the user's own are read from.
+var Dir = filepath.Join("files", "puzzles")
+
+// ErrNotPuzzle is returned when reading a file that isn't a puzzle.
+var ErrNotPuzzle = errors.New("not a Connectron puzzle file")
+
+//go:embed builtin
+var builtin embed.FS
+
+// Puzzle is a position to win from.
+type Puzzle struct {
+	Version     int              `json:"version"`
+	ID          string           `json:"id"` // unique, for keeping track of progress
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Config      types.GameConfig `json:"config"` // board size, line length, players and rules; player types are ignored
+	Board       []string         `json:"board"`  // rows from the top: "." for empty, 1-9 for a player's counter, 0 for player 10
+	ToMove      int              `json:"toMove"` // the player solving it, from 1
+	BombsUsed   []int            `json:"bombsUsed,omitempty"`
+	Moves       int              `json:"moves"` // how many of their own moves they must win within
+}
+
+// GameConfig is the puzzle's rules, with every seat played by a person.
+func (p *Puzzle) GameConfig() types.GameConfig {
+	config := p.Config
+	config.BestOf = 1
+	config.PlayerTypes = make([]int, config.PlayerCount)
+	for i := range config.PlayerTypes {
+		config.PlayerTypes[i] = types.HumanPlayer
+	}
+	config.HintBudget = 0
+	return config
+}
+
+// Grid is the board as a game grid: player numbers from 0, -1 for empty.
+func (p *Puzzle) Grid() [][]int {
+	grid := make([][]int, len(p.Board))
+	for row, line := range p.Board {
+		grid[row] = make([]int, len(line))
+		for col, cell := range line {
+			switch {
+			case cell == '.':
+				grid[row][col] = -1
+			case cell == '0':
+				grid[row][col] = 9
+			default:
+				grid[row][col] = int(cell - '1')
+			}
+		}
+	}
+	return grid
+}
+
+// Validate checks the puzzle describes a position that could be played
+// from. It doesn't check the puzzle can be solved.
+func (p *Puzzle) Validate() error {
+	if p.Version != Version {
+		return fmt.Errorf("%w: unknown version %d", ErrNotPuzzle, p.Version)
+	}
+	if strings.TrimSpace(p.ID) == "" {
+		return fmt.Errorf("%w: no id", ErrNotPuzzle)
+	}
+	// GameConfig seats the players, so check there is a sensible number first
+	if p.Config.PlayerCount < types.MinPlayers || p.Config.PlayerCount > MaxPlayers {
+		return fmt.Errorf("puzzles must have %d-%d players, got %d", types.MinPlayers, MaxPlayers, p.Config.PlayerCount)
+	}
+	config := p.GameConfig()
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if config.GridWidth > MaxSize || config.GridHeight > MaxSize {
+		return fmt.Errorf("puzzle boards can be at most %dx%d, not %dx%d", MaxSize, MaxSize, config.GridWidth, config.GridHeight)
+	}
+	if len(p.Board) != config.GridHeight {
+		return fmt.Errorf("the board has %d rows, not %d", len(p.Board), config.GridHeight)
+	}
+	for row, line := range p.Board {
+		if len(line) != config.GridWidth {
+			return fmt.Errorf("row %d of the board has %d cells, not %d", row+1, len(line), config.GridWidth)
+		}
+		for _, cell := range line {
+			player := strings.IndexRune("1234567890", cell) + 1
+			if cell != '.' && (player < 1 || player > config.PlayerCount) {
+				return fmt.Errorf("row %d of the board has %q, which isn't a player", row+1, cell)
+			}
+		}
+	}
+	if p.ToMove < 1 || p.ToMove > config.PlayerCount {
+		return fmt.Errorf("player %d to move, but there are %d players", p.ToMove, config.PlayerCount)
+	}
+	for _, player := range p.BombsUsed {
+		if player < 1 || player > config.PlayerCount {
+			return fmt.Errorf("player %d has used their bomb, but there are %d players", player, config.PlayerCount)
+		}
+	}
+	if p.Moves < 1 || p.Moves > MaxMoves {
+		return fmt.Errorf("puzzles must be won in 1 to %d moves, not %d", MaxMoves, p.Moves)
+	}
+	return nil
+}
+
+// Read reads and checks a puzzle file.
+func Read(filePath string) (*Puzzle, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parse(data)
+}
+
+func parse(data []byte) (*Puzzle, error) {
+	var p Puzzle
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotPuzzle, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Write saves a puzzle, replacing any file already there.
+func Write(filePath string, p *Puzzle) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// Load reads the built-in puzzles and every .json file in dir, easiest
+// first. Files that can't be read are left out, with an error for each. A
+// puzzle in dir with the same id as a built-in one replaces it.
+func Load(dir string) ([]*Puzzle, []error) {
+	byID := make(map[string]*Puzzle)
+	var errs []error
+	entries, _ := builtin.ReadDir("builtin")
+	for _, entry := range entries {
+		data, err := builtin.ReadFile("builtin/" + entry.Name())
+		if err == nil {
+			var p *Puzzle
+			if p, err = parse(data); err == nil {
+				byID[p.ID] = p
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("built-in puzzle %s: %w", entry.Name(), err))
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, file := range files {
+		p, err := Read(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		byID[p.ID] = p
+	}
+
+	puzzles := make([]*Puzzle, 0, len(byID))
+	for _, p := range byID {
+		puzzles = append(puzzles, p)
+	}
+	sort.Slice(puzzles, func(i, j int) bool {
+		if puzzles[i].Moves != puzzles[j].Moves {
+			return puzzles[i].Moves < puzzles[j].Moves
+		}
+		return puzzles[i].Title < puzzles[j].Title
+	})
+	return puzzles, errs
+}
@@ -0,0 +1,70 @@
+package puzzle
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// puzzleFile is a valid puzzle with the given JSON fields swapped in.
+func puzzleFile(id string, replace ...string) string {
+	file := `{
+  "version": 1,
+  "id": "` + id + `",
+  "title": "Test",
+  "config": {"gridWidth": 7, "gridHeight": 6, "lineLength": 4, "playerCount": 2, "bestOf": 1},
+  "board": [".......", ".......", ".......", ".......", "2...2..", "111.2.."],
+  "toMove": 1,
+  "moves": 1
+}`
+	for i := 0; i+1 < len(replace); i += 2 {
+		file = strings.Replace(file, replace[i], replace[i+1], 1)
+	}
+	return file
+}
+
+func TestLoadSkipsMalformedFiles(t *testing.T) {
+	builtins, errs := Load(t.TempDir())
+	if len(errs) != 0 {
+		t.Fatalf("built-in puzzles don't load: %v", errs)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"good.json":             puzzleFile("mine"),
+		"not-json.json":         "this isn't a puzzle",
+		"negative-players.json": puzzleFile("negative", `"playerCount": 2`, `"playerCount": -1`),
+		"too-many-players.json": puzzleFile("many", `"playerCount": 2`, `"playerCount": 5`),
+		"too-wide.json":         puzzleFile("wide", `"gridWidth": 7`, `"gridWidth": 11`),
+		"old-version.json":      puzzleFile("old", `"version": 1`, `"version": 0`),
+		"no-id.json":            puzzleFile(""),
+		"short-board.json":      puzzleFile("short", `"111.2.."`, `"111.2"`),
+		"missing-row.json":      puzzleFile("missing", `".......", "2...2..",`, `"2...2..",`),
+		"bad-cell.json":         puzzleFile("cell", `"111.2.."`, `"111.x.."`),
+		"third-player.json":     puzzleFile("third", `"111.2.."`, `"111.3.."`),
+		"no-one-to-move.json":   puzzleFile("nobody", `"toMove": 1`, `"toMove": 3`),
+		"too-long.json":         puzzleFile("long", `"moves": 1`, `"moves": 99`),
+		"bad-rules.json":        puzzleFile("rules", `"lineLength": 4`, `"lineLength": 0`),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	puzzles, errs := Load(dir)
+	if len(errs) != len(files)-1 {
+		t.Errorf("got %d errors, want one for each of the %d bad files: %v", len(errs), len(files)-1, errs)
+	}
+	if len(puzzles) != len(builtins)+1 {
+		t.Fatalf("got %d puzzles, want the %d built-in ones and the good file", len(puzzles), len(builtins))
+	}
+	found := false
+	for _, p := range puzzles {
+		found = found || p.ID == "mine"
+	}
+	if !found {
+		t.Error("the good file wasn't loaded")
+	}
+}
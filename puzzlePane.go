@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/puzzle"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// createPuzzlePane builds the tab for "win in N" puzzles: the built-in ones
+// and any in puzzle.Dir, with how the chosen profile has got on with each.
+func createPuzzlePane(a fyne.App) fyne.CanvasObject {
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapWord
+	profileEntry := widget.NewEntry()
+	profileEntry.SetText("Player-1") // progress is kept under leaderboard names
+	profile := func() string { return strings.TrimSpace(profileEntry.Text) }
+
+	progress, err := puzzle.ReadProgress(puzzle.ProgressPath)
+	if err != nil {
+		fmt.Println("Error reading puzzle progress:", err)
+		progress = &puzzle.Progress{Version: puzzle.Version}
+	}
+	var puzzles []*puzzle.Puzzle
+
+	summaryLabel := widget.NewLabel("")
+	list := widget.NewList(
+		func() int { return len(puzzles) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(puzzleListText(puzzles[id], progress.Result(profile(), puzzles[id].ID)))
+		},
+	)
+	refresh := func() {
+		summaryLabel.SetText(fmt.Sprintf("%s has solved %d of %d puzzles.", profile(), progress.Solved(profile()), len(puzzles)))
+		list.Refresh()
+	}
+	reload := func() {
+		var errs []error
+		puzzles, errs = puzzle.Load(puzzle.Dir)
+		var problems []string
+		for _, err := range errs {
+			problems = append(problems, err.Error())
+		}
+		statusLabel.SetText(strings.Join(problems, "\n"))
+		refresh()
+	}
+	profileEntry.OnChanged = func(string) { refresh() }
+
+	list.OnSelected = func(id widget.ListItemID) {
+		list.UnselectAll()
+		if profile() == "" {
+			statusLabel.SetText("Enter a profile name to keep your progress under.")
+			return
+		}
+		p, name := puzzles[id], profile()
+		attempt, err := ui.StartPuzzle(p)
+		if err != nil {
+			statusLabel.SetText(p.Title + ": " + err.Error())
+			return
+		}
+		ui.PuzzleWindow(attempt, a, func(solved bool, mistakes int) {
+			progress.Record(name, p.ID, solved, mistakes)
+			if err := puzzle.WriteProgress(puzzle.ProgressPath, progress); err != nil {
+				fmt.Println("Error saving puzzle progress:", err)
+			}
+			refresh()
+		})
+	}
+
+	reload()
+	top := container.NewVBox(
+		widget.NewLabel("Win from the position in the moves given. Any move that still forces a win is accepted, and the other players defend as well as they can. Add your own puzzles to "+puzzle.Dir+"."),
+		container.NewBorder(nil, nil, widget.NewLabel("Profile:"), widget.NewButton("Reload Puzzles", reload), profileEntry),
+		summaryLabel,
+	)
+	return container.NewBorder(top, statusLabel, nil, nil, list)
+}
+
+// puzzleListText describes a puzzle and how a profile has got on with it.
+func puzzleListText(p *puzzle.Puzzle, result puzzle.Result) string {
+	status := "not tried"
+	switch {
+	case result.Solved:
+		status = fmt.Sprintf("solved, %d attempts, %d mistakes", result.Attempts, result.Mistakes)
+	case result.Attempts > 0:
+		status = fmt.Sprintf("unsolved, %d attempts", result.Attempts)
+	}
+	return fmt.Sprintf("%s (win in %d, %d players) - %s", p.Title, p.Moves, p.Config.PlayerCount, status)
+}
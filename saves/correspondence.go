@@ -0,0 +1,219 @@
+package saves
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// CorrespondenceVersion is written into every move file. Bump it whenever
+// the format changes in a way older builds can't read.
+const CorrespondenceVersion = 1
+
+// Correspondence is a game played by passing a file between players, one
+// move at a time. Every move is chained to the one before by a hash and
+// signed by the player who made it, so changing any part of the history
+// (or the settings) is caught when the file is opened.
+type Correspondence struct {
+	Version int              `json:"version"`
+	ID      string           `json:"id"` // random, so moves can't be copied in from another game
+	Config  types.GameConfig `json:"config"`
+	Moves   []SignedMove     `json:"moves"`
+}
+
+// SignedMove is one move in a correspondence game.
+type SignedMove struct {
+	Move      types.Move `json:"move"`
+	Key       string     `json:"key"`       // public key of the player who made it, hex
+	Hash      string     `json:"hash"`      // covers this move and everything before it, hex
+	Signature string     `json:"signature"` // of Hash by Key, hex
+}
+
+// Errors returned when a move file doesn't check out.
+var (
+	ErrNotCorrespondence = errors.New("not a correspondence move file")
+	ErrTampered          = errors.New("the move file has been changed since it was signed")
+	ErrWrongSigner       = errors.New("move signed by someone other than that player")
+)
+
+// NewCorrespondence starts a correspondence game with the given settings.
+// Every seat must be a person, since there is no one to run the AI.
+func NewCorrespondence(config types.GameConfig) (*Correspondence, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	for i, playerType := range config.PlayerTypes[:config.PlayerCount] {
+		if playerType != types.HumanPlayer {
+			return nil, fmt.Errorf("player %d must be a human for a correspondence game", i+1)
+		}
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &Correspondence{Version: CorrespondenceVersion, ID: hex.EncodeToString(id), Config: config}, nil
+}
+
+// Head is the hash of the newest move, or of the settings if nobody has moved.
+func (c *Correspondence) Head() string {
+	if len(c.Moves) == 0 {
+		return c.genesis()
+	}
+	return c.Moves[len(c.Moves)-1].Hash
+}
+
+// genesis hashes the game's identity and settings, which every move's hash
+// then builds on.
+func (c *Correspondence) genesis() string {
+	config, _ := json.Marshal(c.Config)
+	sum := sha256.Sum256(append([]byte(c.ID+"\n"), config...))
+	return hex.EncodeToString(sum[:])
+}
+
+// moveHash chains a move onto the hash before it.
+func moveHash(previous string, move types.Move, key string) string {
+	data, _ := json.Marshal(move)
+	sum := sha256.Sum256([]byte(previous + "\n" + key + "\n" + string(data)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Append signs a move and adds it to the game. It doesn't check the move
+// against the rules; that is up to the caller.
+func (c *Correspondence) Append(move types.Move, key ed25519.PrivateKey) {
+	public := KeyID(key)
+	hash := moveHash(c.Head(), move, public)
+	signature := ed25519.Sign(key, []byte(hash))
+	c.Moves = append(c.Moves, SignedMove{Move: move, Key: public, Hash: hash, Signature: hex.EncodeToString(signature)})
+}
+
+// KeyID is how a player's key appears in move files.
+func KeyID(key ed25519.PrivateKey) string {
+	return hex.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// Verify checks the hash chain and every signature, and that each seat's
+// moves were all signed by the same key. A key may only play one seat, so
+// nobody can make a first move for another player.
+func (c *Correspondence) Verify() error {
+	if c.Version != CorrespondenceVersion || c.ID == "" {
+		return ErrNotCorrespondence
+	}
+	seatKeys := make(map[int]string)
+	keySeats := make(map[string]int)
+	previous := c.genesis()
+	for i, signed := range c.Moves {
+		if moveHash(previous, signed.Move, signed.Key) != signed.Hash {
+			return fmt.Errorf("move %d: %w", i+1, ErrTampered)
+		}
+		public, err := hex.DecodeString(signed.Key)
+		if err != nil || len(public) != ed25519.PublicKeySize {
+			return fmt.Errorf("move %d: bad key: %w", i+1, ErrTampered)
+		}
+		signature, err := hex.DecodeString(signed.Signature)
+		if err != nil || !ed25519.Verify(public, []byte(signed.Hash), signature) {
+			return fmt.Errorf("move %d: bad signature: %w", i+1, ErrTampered)
+		}
+		if key, ok := seatKeys[signed.Move.Player]; ok && key != signed.Key {
+			return fmt.Errorf("move %d by player %d: %w", i+1, signed.Move.Player+1, ErrWrongSigner)
+		}
+		if seat, ok := keySeats[signed.Key]; ok && seat != signed.Move.Player {
+			return fmt.Errorf("move %d by player %d: %w", i+1, signed.Move.Player+1, ErrWrongSigner)
+		}
+		seatKeys[signed.Move.Player] = signed.Key
+		keySeats[signed.Key] = signed.Move.Player
+		previous = signed.Hash
+	}
+	return nil
+}
+
+// CanSign reports whether a key may make the given player's next move: it
+// must be the key that made their earlier moves, and not another seat's.
+func (c *Correspondence) CanSign(player int, key ed25519.PrivateKey) bool {
+	id := KeyID(key)
+	for _, signed := range c.Moves {
+		if signed.Move.Player == player && signed.Key != id || signed.Move.Player != player && signed.Key == id {
+			return false
+		}
+	}
+	return true
+}
+
+// Extends reports whether the game carries on from an earlier copy of it
+// that had count moves ending at head. This catches a file being sent back
+// with its newest moves cut off, which the signatures alone can't.
+func (c *Correspondence) Extends(count int, head string) bool {
+	if count > len(c.Moves) {
+		return false
+	}
+	if count == 0 {
+		return head == c.genesis()
+	}
+	return c.Moves[count-1].Hash == head
+}
+
+// PlainMoves lists the moves without their signatures.
+func (c *Correspondence) PlainMoves() []types.Move {
+	moves := make([]types.Move, len(c.Moves))
+	for i, signed := range c.Moves {
+		moves[i] = signed.Move
+	}
+	return moves
+}
+
+// ReadCorrespondence reads a move file. The result still needs verifying.
+func ReadCorrespondence(filePath string) (*Correspondence, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var c Correspondence
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotCorrespondence, err)
+	}
+	return &c, nil
+}
+
+// WriteCorrespondence writes a move file, replacing any file already there.
+func WriteCorrespondence(filePath string, c *Correspondence) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// LoadOrCreateKey reads the player's signing key, creating one the first
+// time. The same key must be used for all of a player's moves in a game.
+func LoadOrCreateKey(filePath string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(filePath)
+	if err == nil {
+		seed, err := hex.DecodeString(string(data))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("signing key %s is damaged", filePath)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filePath, []byte(hex.EncodeToString(key.Seed())), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,57 @@
+package saves
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// GameVersion is written into every saved game. Bump it whenever the format
+// changes in a way older builds can't read.
+const GameVersion = 1
+
+// ErrNotSavedGame is returned when a file isn't a saved game.
+var ErrNotSavedGame = errors.New("not a saved Connectron game")
+
+// GameRecord is a saved game: its settings and every move of the series in
+// order, which is enough to rebuild the board or replay it move by move.
+type GameRecord struct {
+	Version int                 `json:"version"`
+	Saved   time.Time           `json:"saved"`
+	Config  types.GameConfig    `json:"config"`
+	Moves   []types.Move        `json:"moves"`
+	Chat    []types.ChatMessage `json:"chat,omitempty"`
+}
+
+// ReadGame reads a saved game.
+func ReadGame(filePath string) (GameRecord, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return GameRecord{}, err
+	}
+	var record GameRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return GameRecord{}, fmt.Errorf("%w: %v", ErrNotSavedGame, err)
+	}
+	if record.Version != GameVersion {
+		return GameRecord{}, fmt.Errorf("%w: unknown version %d", ErrNotSavedGame, record.Version)
+	}
+	return record, nil
+}
+
+// WriteGame saves a game, replacing any file already there.
+func WriteGame(filePath string, record GameRecord) error {
+	record.Version = GameVersion
+	if record.Saved.IsZero() {
+		record.Saved = time.Now()
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
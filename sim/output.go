@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// WriteJSON writes the statistics as indented JSON.
+func WriteJSON(w io.Writer, stats Stats) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stats)
+}
+
+// WriteCSV writes the statistics as a header and a single row, with the
+// settings first so rows from different runs can be put side by side.
+func WriteCSV(w io.Writer, stats Stats) error {
+	c := stats.Config
+	names := make([]string, c.PlayerCount)
+	for i, playerType := range c.PlayerTypes[:c.PlayerCount] {
+		names[i] = types.PlayerTypeName(playerType)
+	}
+	header := []string{
+		"width", "height", "line", "players", "types",
+		"corner", "solitaire", "bomb", "overflow", "alliances",
+		"games", "seed", "draws", "draw_rate", "unfinished", "average_length",
+		"solitaire_removals", "overflows", "bomb_uses",
+	}
+	row := []string{
+		strconv.Itoa(c.GridWidth), strconv.Itoa(c.GridHeight), strconv.Itoa(c.LineLength), strconv.Itoa(c.PlayerCount), strings.Join(names, " vs "),
+		strconv.FormatBool(c.CornerBonus), strconv.FormatBool(c.SolitaireRule), strconv.FormatBool(c.BombCounter), strconv.FormatBool(c.OverflowRule), alliancesText(c.EnableAlliances, c.Alliances),
+		strconv.Itoa(stats.Games), strconv.FormatInt(stats.Seed, 10), strconv.Itoa(stats.Draws), formatRate(stats.DrawRate), strconv.Itoa(stats.Unfinished), strconv.FormatFloat(stats.AverageLength, 'f', 2, 64),
+		strconv.Itoa(stats.SolitaireRemovals), strconv.Itoa(stats.Overflows), strconv.Itoa(stats.BombUses),
+	}
+	for seat := range stats.Wins {
+		header = append(header, fmt.Sprintf("p%d_wins", seat+1), fmt.Sprintf("p%d_win_rate", seat+1))
+		row = append(row, strconv.Itoa(stats.Wins[seat]), formatRate(stats.WinRates[seat]))
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	writer.Write(row)
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', 4, 64)
+}
+
+// alliancesText writes alliances the way the -alliances flag takes them
+func alliancesText(enabled bool, alliances [][]string) string {
+	if !enabled {
+		return ""
+	}
+	var groups []string
+	for _, alliance := range alliances {
+		numbers := make([]string, len(alliance))
+		for i, player := range alliance {
+			numbers[i] = strings.TrimPrefix(player, "Player-")
+		}
+		groups = append(groups, strings.Join(numbers, ","))
+	}
+	return strings.Join(groups, ";")
+}
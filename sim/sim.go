@@ -0,0 +1,160 @@
+// Package sim plays AI-only games in bulk and sums up how they went, for
+// comparing rule combinations and AI levels without a window.
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// maxMovesPerCell stops games the special rules keep from ever filling the
+// board. They are counted as unfinished rather than as draws.
+const maxMovesPerCell = 4
+
+// ErrNoGames is returned by Run when asked for fewer than one game.
+var ErrNoGames = errors.New("at least one game must be played")
+
+// Options say what to simulate. Each game is a single round, whatever the
+// config's best of.
+type Options struct {
+	Config  types.GameConfig
+	Games   int
+	Workers int   // games played at once; 0 for one per CPU
+	Seed    int64 // game i is played with Seed+i, so results don't depend on Workers
+}
+
+// Stats sums up a batch of games.
+type Stats struct {
+	Config            types.GameConfig `json:"config"`
+	Games             int              `json:"games"`
+	Seed              int64            `json:"seed"`
+	Wins              []int            `json:"wins"` // by seat
+	WinRates          []float64        `json:"winRates"`
+	Draws             int              `json:"draws"`
+	DrawRate          float64          `json:"drawRate"`
+	Unfinished        int              `json:"unfinished,omitempty"`
+	AverageLength     float64          `json:"averageLength"` // moves per game
+	SolitaireRemovals int              `json:"solitaireRemovals"`
+	Overflows         int              `json:"overflows"`
+	BombUses          int              `json:"bombUses"`
+}
+
+// result is how a single game went.
+type result struct {
+	winner    int // seat number from 1, 0 for a draw, -1 if unfinished
+	moves     int
+	solitaire int
+	overflows int
+	bombs     int
+	err       error
+}
+
+// Run plays the games, several at once, and gathers their statistics.
+func Run(opts Options) (Stats, error) {
+	config := opts.Config
+	config.BestOf = 1
+	if err := config.Validate(); err != nil {
+		return Stats{}, err
+	}
+	for i, playerType := range config.PlayerTypes[:config.PlayerCount] {
+		if !types.IsAI(playerType) {
+			return Stats{}, fmt.Errorf("player %d must be an AI to simulate", i+1)
+		}
+	}
+	if opts.Games < 1 {
+		return Stats{}, ErrNoGames
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, opts.Games)
+
+	results := make([]result, opts.Games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = playGame(config, opts.Seed+int64(i))
+			}
+		}()
+	}
+	for i := range results {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	stats := Stats{
+		Config: config,
+		Games:  opts.Games,
+		Seed:   opts.Seed,
+		Wins:   make([]int, config.PlayerCount),
+	}
+	totalMoves := 0
+	for i, r := range results {
+		if r.err != nil {
+			return Stats{}, fmt.Errorf("game %d: %w", i+1, r.err)
+		}
+		switch {
+		case r.winner > 0:
+			stats.Wins[r.winner-1]++
+		case r.winner == 0:
+			stats.Draws++
+		default:
+			stats.Unfinished++
+		}
+		totalMoves += r.moves
+		stats.SolitaireRemovals += r.solitaire
+		stats.Overflows += r.overflows
+		stats.BombUses += r.bombs
+	}
+	games := float64(opts.Games)
+	stats.WinRates = make([]float64, config.PlayerCount)
+	for seat, wins := range stats.Wins {
+		stats.WinRates[seat] = float64(wins) / games
+	}
+	stats.DrawRate = float64(stats.Draws) / games
+	stats.AverageLength = float64(totalMoves) / games
+	return stats, nil
+}
+
+// playGame plays one round through the rules engine, just as the game
+// window would with every seat an AI.
+func playGame(config types.GameConfig, seed int64) result {
+	gw := ui.NewGameFromConfig(config)
+	gw.SetSeed(seed)
+	defer gw.CloseEngines()
+	limit := maxMovesPerCell * config.GridWidth * config.GridHeight
+
+	var r result
+	for !gw.RoundOver {
+		if r.moves == limit {
+			r.winner = -1
+			return r
+		}
+		turn, err := gw.PlayMove(gw.AIMove(gw.PlayerTypes[gw.CurrentTurn]))
+		if err != nil {
+			r.err = fmt.Errorf("move %d: %w", r.moves+1, err)
+			return r
+		}
+		r.moves++
+		r.solitaire += turn.Solitaire
+		if turn.Overflow {
+			r.overflows++
+		}
+		if turn.Bomb {
+			r.bombs++
+		}
+	}
+	r.winner = gw.Winners[0]
+	return r
+}
@@ -0,0 +1,151 @@
+package tournament
+
+// drawRoundRobin draws every round at once using the circle method: one
+// entrant stays put while the rest rotate around them. With an odd number
+// of entrants, whoever is paired with the empty seat has a bye.
+func (t *Tournament) drawRoundRobin() {
+	seats := make([]int, len(t.Entrants))
+	for i := range seats {
+		seats[i] = i
+	}
+	if len(seats)%2 == 1 {
+		seats = append(seats, Bye)
+	}
+	n := len(seats)
+	t.Rounds = n - 1
+
+	for round := 1; round <= t.Rounds; round++ {
+		for i := 0; i < n/2; i++ {
+			home, away := seats[i], seats[n-1-i]
+			if home == Bye || away == Bye {
+				continue // nobody to play; round robin byes don't score
+			}
+			// Swap sides every other round so nobody is always player 1
+			if (round+i)%2 == 0 {
+				home, away = away, home
+			}
+			t.Matches = append(t.Matches, &Match{Round: round, Home: home, Away: away})
+		}
+		// Keep the first seat fixed and rotate the others one place
+		last := seats[n-1]
+		copy(seats[2:], seats[1:n-1])
+		seats[1] = last
+	}
+}
+
+// drawKnockout draws the first round of a single-elimination bracket. The
+// bracket is filled out to a power of two with byes, which go to the top
+// seeds, and the seeds are placed so the top two can only meet in the final.
+func (t *Tournament) drawKnockout() {
+	t.Rounds = log2Ceil(len(t.Entrants))
+	order := []int{1}
+	for len(order) < 1<<t.Rounds {
+		size := 2 * len(order)
+		next := make([]int, 0, size)
+		for _, seed := range order {
+			next = append(next, seed, size+1-seed)
+		}
+		order = next
+	}
+
+	for i := 0; i < len(order); i += 2 {
+		home, away := order[i]-1, order[i+1]-1
+		if away >= len(t.Entrants) {
+			away = Bye
+		}
+		t.Matches = append(t.Matches, &Match{Round: 1, Home: home, Away: away})
+	}
+}
+
+// drawNextKnockout pairs the winners of neighbouring matches in the bracket.
+func (t *Tournament) drawNextKnockout() {
+	round := t.CurrentRound()
+	var winners []int
+	for _, m := range t.Matches {
+		if m.Round == round {
+			winner, _ := t.Result(m)
+			winners = append(winners, winner)
+		}
+	}
+	for i := 0; i+1 < len(winners); i += 2 {
+		t.Matches = append(t.Matches, &Match{Round: round + 1, Home: winners[i], Away: winners[i+1]})
+	}
+}
+
+// drawSwiss pairs entrants on the same score against each other, avoiding
+// rematches where it can. The first round pairs the top half of the seeds
+// with the bottom half. With an odd number of entrants the lowest ranked
+// entrant who hasn't had a bye gets one, which counts as a win.
+func (t *Tournament) drawSwiss(round int) {
+	var ranked []int
+	if round == 1 {
+		for i := range t.Entrants {
+			ranked = append(ranked, i)
+		}
+		half := (len(ranked) + 1) / 2
+		if len(ranked)%2 == 1 {
+			t.Matches = append(t.Matches, &Match{Round: 1, Home: ranked[len(ranked)-1], Away: Bye})
+			ranked = ranked[:len(ranked)-1]
+			half = len(ranked) / 2
+		}
+		for i := 0; i < half; i++ {
+			t.Matches = append(t.Matches, &Match{Round: 1, Home: ranked[i], Away: ranked[half+i]})
+		}
+		return
+	}
+
+	for _, standing := range t.Standings() {
+		ranked = append(ranked, standing.Entrant)
+	}
+	played := make(map[[2]int]bool)
+	hadBye := make(map[int]bool)
+	for _, m := range t.Matches {
+		if m.Away == Bye {
+			hadBye[m.Home] = true
+			continue
+		}
+		played[[2]int{m.Home, m.Away}] = true
+		played[[2]int{m.Away, m.Home}] = true
+	}
+
+	if len(ranked)%2 == 1 {
+		bye := len(ranked) - 1
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if !hadBye[ranked[i]] {
+				bye = i
+				break
+			}
+		}
+		t.Matches = append(t.Matches, &Match{Round: round, Home: ranked[bye], Away: Bye})
+		ranked = append(ranked[:bye:bye], ranked[bye+1:]...)
+	}
+
+	pairs, ok := pairUp(ranked, played)
+	if !ok {
+		// Everyone has played everyone they could; allow rematches
+		pairs, _ = pairUp(ranked, nil)
+	}
+	for _, pair := range pairs {
+		t.Matches = append(t.Matches, &Match{Round: round, Home: pair[0], Away: pair[1]})
+	}
+}
+
+// pairUp pairs each entrant, in ranked order, with the highest ranked one
+// below them they haven't played, backtracking when that leaves someone
+// further down with nobody new to play.
+func pairUp(ranked []int, played map[[2]int]bool) ([][2]int, bool) {
+	if len(ranked) == 0 {
+		return nil, true
+	}
+	home := ranked[0]
+	for i, away := range ranked[1:] {
+		if played[[2]int{home, away}] {
+			continue
+		}
+		rest := append(append([]int(nil), ranked[1:i+1]...), ranked[i+2:]...)
+		if pairs, ok := pairUp(rest, played); ok {
+			return append([][2]int{{home, away}}, pairs...), true
+		}
+	}
+	return nil, false
+}
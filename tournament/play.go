@@ -0,0 +1,32 @@
+package tournament
+
+import (
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// IsAIMatch reports whether both sides of a match are AIs, so it can be
+// played without anyone watching.
+func (t *Tournament) IsAIMatch(m *Match) bool {
+	return m.Away != Bye && types.IsAI(t.Entrants[m.Home].PlayerType) && types.IsAI(t.Entrants[m.Away].PlayerType)
+}
+
+// PlayAI plays an AI-only match through the rules engine and records it,
+// returning the round winners.
+func (t *Tournament) PlayAI(m *Match) ([]int, error) {
+	gw := ui.NewGameFromConfig(t.MatchConfig(m))
+	defer gw.CloseEngines()
+	for {
+		if gw.RoundOver {
+			if gw.SeriesOver() {
+				break
+			}
+			gw = gw.NextRound()
+		}
+		if _, err := gw.PlayMove(gw.AIMove(gw.PlayerTypes[gw.CurrentTurn])); err != nil {
+			return nil, err
+		}
+	}
+	_, err := t.Record(m, gw.Winners)
+	return gw.Winners, err
+}
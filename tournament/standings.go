@@ -0,0 +1,103 @@
+package tournament
+
+import "sort"
+
+// Standing is how an entrant is doing. A match win is worth a point and a
+// drawn match half a point; a Swiss bye counts as a win.
+type Standing struct {
+	Entrant    int // index into Entrants
+	Played     int // matches, not counting byes
+	Won        int
+	Drawn      int
+	Lost       int
+	Byes       int
+	Points     float64
+	Buchholz   float64 // Swiss tiebreak: the total points of everyone they played
+	RoundsWon  int
+	RoundsLost int
+	Out        bool // knocked out
+}
+
+// Standings ranks the entrants by points, then the Swiss tiebreak, then
+// rounds won minus rounds lost, then rounds won, then seeding.
+func (t *Tournament) Standings() []Standing {
+	standings := make([]Standing, len(t.Entrants))
+	for i := range standings {
+		standings[i].Entrant = i
+	}
+	opponents := make([][]int, len(t.Entrants))
+
+	for _, m := range t.Matches {
+		winner, done := t.Result(m)
+		if m.Away == Bye {
+			standings[m.Home].Byes++
+			if t.Format == Swiss {
+				standings[m.Home].Points++
+			}
+			continue
+		}
+		home, away := m.Score()
+		standings[m.Home].RoundsWon += home
+		standings[m.Home].RoundsLost += away
+		standings[m.Away].RoundsWon += away
+		standings[m.Away].RoundsLost += home
+		if !done {
+			continue
+		}
+		opponents[m.Home] = append(opponents[m.Home], m.Away)
+		opponents[m.Away] = append(opponents[m.Away], m.Home)
+		for _, entrant := range []int{m.Home, m.Away} {
+			s := &standings[entrant]
+			s.Played++
+			switch winner {
+			case entrant:
+				s.Won++
+				s.Points++
+			case -1:
+				s.Drawn++
+				s.Points += 0.5
+			default:
+				s.Lost++
+				s.Out = t.Format == Knockout
+			}
+		}
+	}
+
+	if t.Format == Swiss {
+		for i := range standings {
+			for _, opponent := range opponents[i] {
+				standings[i].Buchholz += standings[opponent].Points
+			}
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		switch {
+		case a.Points != b.Points:
+			return a.Points > b.Points
+		case a.Buchholz != b.Buchholz:
+			return a.Buchholz > b.Buchholz
+		case a.RoundsWon-a.RoundsLost != b.RoundsWon-b.RoundsLost:
+			return a.RoundsWon-a.RoundsLost > b.RoundsWon-b.RoundsLost
+		case a.RoundsWon != b.RoundsWon:
+			return a.RoundsWon > b.RoundsWon
+		}
+		return a.Entrant < b.Entrant
+	})
+	return standings
+}
+
+// Champion is the tournament's winner once it is finished: the knockout
+// final's winner, or whoever tops the standings.
+func (t *Tournament) Champion() (Entrant, bool) {
+	if !t.Finished() {
+		return Entrant{}, false
+	}
+	if t.Format == Knockout {
+		final := t.Matches[len(t.Matches)-1]
+		winner, _ := t.Result(final)
+		return t.Entrants[winner], true
+	}
+	return t.Entrants[t.Standings()[0].Entrant], true
+}
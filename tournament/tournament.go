@@ -0,0 +1,264 @@
+// Package tournament runs round-robin, knockout and Swiss tournaments made
+// of two-player matches, each a best-of-N series.
+package tournament
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Version is written into every saved tournament. Bump it whenever the
+// format changes in a way older builds can't read.
+const Version = 1
+
+// Format is how entrants are paired.
+type Format string
+
+const (
+	RoundRobin Format = "round-robin" // everyone plays everyone once
+	Knockout   Format = "knockout"    // single elimination; level series go to sudden death
+	Swiss      Format = "swiss"       // a few rounds, pairing entrants on the same score
+)
+
+// MaxSuddenDeath is how many single rounds a level knockout series goes on
+// for. If it is still level after them, the higher seed goes through, so
+// two AIs that draw every game can't play forever.
+const MaxSuddenDeath = 3
+
+// Bye stands in for the missing opponent when an entrant sits a round out.
+const Bye = -1
+
+// Errors returned when a tournament can't be set up or a result recorded.
+var (
+	ErrTooFewEntrants = errors.New("a tournament needs at least two entrants")
+	ErrNotTournament  = errors.New("not a saved Connectron tournament")
+	ErrMatchFinished  = errors.New("that match has already been played")
+	ErrNotInRound     = errors.New("that match isn't in the current round")
+)
+
+// Entrant is someone, or some AI, taking part.
+type Entrant struct {
+	Name       string `json:"name"`
+	PlayerType int    `json:"playerType"` // HumanPlayer or an AI level
+}
+
+// Match is a series between two entrants. Home plays as player 1.
+type Match struct {
+	Round   int   `json:"round"`             // from 1
+	Home    int   `json:"home"`              // index into Entrants
+	Away    int   `json:"away"`              // index into Entrants, or Bye
+	Winners []int `json:"winners,omitempty"` // each round's winner as in Game.Winners: 1 home, 2 away, 0 draw
+}
+
+// Score counts the rounds each side has won.
+func (m *Match) Score() (home, away int) {
+	for _, winner := range m.Winners {
+		switch winner {
+		case 1:
+			home++
+		case 2:
+			away++
+		}
+	}
+	return home, away
+}
+
+// Tournament is a whole tournament, saved between sessions.
+type Tournament struct {
+	Version  int              `json:"version"`
+	Name     string           `json:"name"`
+	Format   Format           `json:"format"`
+	Config   types.GameConfig `json:"config"` // the rules and best of for every match
+	Entrants []Entrant        `json:"entrants"`
+	Rounds   int              `json:"rounds"`  // how many rounds there will be
+	Matches  []*Match         `json:"matches"` // in the order they were drawn
+}
+
+// New sets up a tournament and draws the first round. Entrants are seeded
+// in the order given. swissRounds is only used by Swiss tournaments; 0
+// picks enough rounds to find a clear winner.
+func New(name string, format Format, config types.GameConfig, entrants []Entrant, swissRounds int) (*Tournament, error) {
+	if len(entrants) < 2 {
+		return nil, ErrTooFewEntrants
+	}
+	names := make(map[string]bool)
+	for _, entrant := range entrants {
+		if strings.TrimSpace(entrant.Name) == "" {
+			return nil, errors.New("every entrant needs a name")
+		}
+		if names[entrant.Name] {
+			return nil, fmt.Errorf("%s is entered twice", entrant.Name)
+		}
+		names[entrant.Name] = true
+		if entrant.PlayerType != types.HumanPlayer && !types.IsAI(entrant.PlayerType) {
+			return nil, fmt.Errorf("%s must be a person or an AI", entrant.Name)
+		}
+	}
+
+	// Every match is a two-player game with the chosen rules
+	config.PlayerCount = 2
+	config.PlayerTypes = []int{types.HumanPlayer, types.HumanPlayer}
+	config.EnableAlliances = false
+	config.Alliances = nil
+	config.AIForMissing = false
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	t := &Tournament{
+		Version:  Version,
+		Name:     name,
+		Format:   format,
+		Config:   config,
+		Entrants: entrants,
+	}
+	switch format {
+	case RoundRobin:
+		t.drawRoundRobin()
+	case Knockout:
+		t.drawKnockout()
+	case Swiss:
+		t.Rounds = swissRounds
+		if t.Rounds <= 0 {
+			t.Rounds = log2Ceil(len(entrants))
+		}
+		t.Rounds = min(t.Rounds, len(entrants)-1)
+		t.drawSwiss(1)
+	default:
+		return nil, fmt.Errorf("unknown tournament format %q", format)
+	}
+	return t, nil
+}
+
+// MatchConfig is the game settings for playing a match: the tournament's
+// rules with the two entrants seated. A knockout series that ended level
+// is followed by single rounds until someone wins one, up to
+// MaxSuddenDeath of them.
+func (t *Tournament) MatchConfig(m *Match) types.GameConfig {
+	config := t.Config
+	config.PlayerTypes = []int{t.Entrants[m.Home].PlayerType, t.Entrants[m.Away].PlayerType}
+	if len(m.Winners) > 0 {
+		config.BestOf = 1
+	}
+	return config
+}
+
+// Result says how a match finished. winner is an entrant index, or -1 for
+// a draw; done is false while it is still to be played. A knockout match
+// still level after sudden death goes to the higher seed.
+func (t *Tournament) Result(m *Match) (winner int, done bool) {
+	if m.Away == Bye {
+		return m.Home, true
+	}
+	home, away := m.Score()
+	switch {
+	case home > t.Config.BestOf/2 || home > away && len(m.Winners) >= t.Config.BestOf:
+		return m.Home, true
+	case away > t.Config.BestOf/2 || away > home && len(m.Winners) >= t.Config.BestOf:
+		return m.Away, true
+	case len(m.Winners) >= t.Config.BestOf && t.Format != Knockout:
+		return -1, true
+	case len(m.Winners) >= t.Config.BestOf+MaxSuddenDeath:
+		return min(m.Home, m.Away), true // entrants are in seeding order
+	}
+	return -1, false
+}
+
+// CurrentRound is the earliest round with matches still to play, or the
+// last round drawn once they have all been played.
+func (t *Tournament) CurrentRound() int {
+	round, last := 0, 0
+	for _, m := range t.Matches {
+		last = max(last, m.Round)
+		if _, done := t.Result(m); !done && (round == 0 || m.Round < round) {
+			round = m.Round
+		}
+	}
+	if round == 0 {
+		return last
+	}
+	return round
+}
+
+// Pending lists the current round's matches that are still to be played.
+func (t *Tournament) Pending() []*Match {
+	var pending []*Match
+	round := t.CurrentRound()
+	for _, m := range t.Matches {
+		if _, done := t.Result(m); m.Round == round && !done {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// Finished reports whether every round has been played.
+func (t *Tournament) Finished() bool {
+	return t.CurrentRound() >= t.Rounds && len(t.Pending()) == 0
+}
+
+// Record adds the round winners from playing a match, as in Game.Winners,
+// and draws the next round once the current one is complete. It reports
+// whether the match is now decided.
+func (t *Tournament) Record(m *Match, winners []int) (bool, error) {
+	if _, done := t.Result(m); done {
+		return true, ErrMatchFinished
+	}
+	if m.Round != t.CurrentRound() {
+		return false, ErrNotInRound
+	}
+	m.Winners = append(m.Winners, winners...)
+	_, done := t.Result(m)
+	if len(t.Pending()) == 0 && t.CurrentRound() < t.Rounds {
+		switch t.Format {
+		case Knockout:
+			t.drawNextKnockout()
+		case Swiss:
+			t.drawSwiss(t.CurrentRound() + 1)
+		}
+	}
+	return done, nil
+}
+
+// Read reads a saved tournament.
+func Read(filePath string) (*Tournament, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var t Tournament
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotTournament, err)
+	}
+	if t.Version != Version {
+		return nil, fmt.Errorf("%w: unknown version %d", ErrNotTournament, t.Version)
+	}
+	return &t, nil
+}
+
+// Write saves a tournament, replacing any file already there.
+func Write(filePath string, t *Tournament) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// log2Ceil is the number of knockout rounds needed for n entrants.
+func log2Ceil(n int) int {
+	rounds := 0
+	for size := 1; size < n; size *= 2 {
+		rounds++
+	}
+	return rounds
+}
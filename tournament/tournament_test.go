@@ -0,0 +1,63 @@
+package tournament
+
+import (
+	"testing"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// drawnConfig can't be won: the line is longer than the board is wide or
+// high, so every game is a draw.
+var drawnConfig = types.GameConfig{GridWidth: 6, GridHeight: 6, LineLength: 10, PlayerCount: 2, BestOf: 3}
+
+func TestDrawnKnockoutGoesToHigherSeed(t *testing.T) {
+	entrants := []Entrant{{"First", types.MediumAI}, {"Second", types.MediumAI}}
+	tour, err := New("Level", Knockout, drawnConfig, entrants, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := tour.Matches[0]
+
+	if _, err := tour.Record(m, []int{0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < MaxSuddenDeath; i++ {
+		if _, done := tour.Result(m); done {
+			t.Fatalf("decided after %d sudden death rounds, want %d", i, MaxSuddenDeath)
+		}
+		if bestOf := tour.MatchConfig(m).BestOf; bestOf != 1 {
+			t.Fatalf("sudden death is best of %d, want 1", bestOf)
+		}
+		if _, err := tour.Record(m, []int{0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if winner, done := tour.Result(m); !done || winner != 0 {
+		t.Fatalf("got winner %d, done %v; want the higher seed through", winner, done)
+	}
+	if champion, ok := tour.Champion(); !ok || champion.Name != "First" {
+		t.Fatalf("got champion %+v, %v; want First", champion, ok)
+	}
+}
+
+func TestPlayAIDrawnKnockoutFinishes(t *testing.T) {
+	entrants := []Entrant{{"First", types.MediumAI}, {"Second", types.MediumAI}}
+	tour, err := New("Level", Knockout, drawnConfig, entrants, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As the tournament tab's Play All AI Matches does
+	for games := 0; !tour.Finished(); games++ {
+		if games > drawnConfig.BestOf+MaxSuddenDeath {
+			t.Fatal("the match is still going after sudden death")
+		}
+		for _, m := range tour.Pending() {
+			if _, err := tour.PlayAI(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if len(tour.Matches[0].Winners) != drawnConfig.BestOf+MaxSuddenDeath {
+		t.Fatalf("played %d rounds, want %d", len(tour.Matches[0].Winners), drawnConfig.BestOf+MaxSuddenDeath)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/tournament"
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// tournamentPath is where the tournament in progress is kept between sessions
+var tournamentPath = filepath.Join("files", "tournament.json")
+
+// Tournament formats as shown in the format dropdown
+var tournamentFormats = map[string]tournament.Format{
+	"Round Robin": tournament.RoundRobin,
+	"Knockout":    tournament.Knockout,
+	"Swiss":       tournament.Swiss,
+}
+
+// createTournamentPane builds the tab for running a tournament. Matches use
+// the rules currently chosen on the Setup Game tab.
+func createTournamentPane(a fyne.App, parent fyne.Window, currentConfig func() types.GameConfig) fyne.CanvasObject {
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapWord
+	view := container.NewVBox()
+	current, _ := tournament.Read(tournamentPath)
+
+	var refresh func()
+	save := func() {
+		if err := tournament.Write(tournamentPath, current); err != nil {
+			statusLabel.SetText("Could not save the tournament: " + err.Error())
+		}
+	}
+
+	// finished saves a match's result, and adds it to the leaderboard once decided
+	finished := func(m *tournament.Match) {
+		if winner, done := current.Result(m); done {
+			name := ""
+			if winner >= 0 {
+				name = current.Entrants[winner].Name
+			}
+			if err := ui.RecordMatch(current.Entrants[m.Home].Name, current.Entrants[m.Away].Name, name); err != nil {
+				fmt.Println("Error updating leaderboard:", err)
+			}
+		}
+		save()
+		refresh()
+	}
+
+	// Matches being played in a game window, which can't be started again
+	playing := make(map[*tournament.Match]bool)
+	play := func(m *tournament.Match) {
+		if playing[m] {
+			return
+		}
+		if current.IsAIMatch(m) {
+			if _, err := current.PlayAI(m); err != nil {
+				statusLabel.SetText("Could not play the match: " + err.Error())
+				return
+			}
+			finished(m)
+			return
+		}
+		t := current
+		gw := ui.NewGameFromConfig(t.MatchConfig(m))
+		gw.OnSeriesOver = func(winners []int) {
+			if t != current {
+				return // a new tournament was started meanwhile
+			}
+			if _, err := t.Record(m, winners); err != nil {
+				statusLabel.SetText("Could not record the match: " + err.Error())
+				return
+			}
+			finished(m)
+		}
+		gw.OnClosed = func() {
+			delete(playing, m)
+			refresh()
+		}
+		playing[m] = true
+		refresh()
+		ui.MainGameWindow(gw, a)
+	}
+
+	playAIButton := widget.NewButton("Play All AI Matches", func() {
+		// Each round is drawn once the last one is over, so keep going
+		// until only matches with people in are left
+		for current != nil && !current.Finished() {
+			played := false
+			for _, m := range current.Pending() {
+				if !current.IsAIMatch(m) {
+					continue
+				}
+				if _, err := current.PlayAI(m); err != nil {
+					statusLabel.SetText("Could not play the match: " + err.Error())
+					return
+				}
+				played = true
+				finished(m)
+			}
+			if !played {
+				break
+			}
+		}
+	})
+
+	refresh = func() {
+		view.RemoveAll()
+		if current == nil {
+			view.Add(widget.NewLabel("No tournament yet. Set one up below."))
+			playAIButton.Disable()
+			return
+		}
+		playAIButton.Enable()
+
+		heading := fmt.Sprintf("%s - round %d of %d", current.Name, current.CurrentRound(), current.Rounds)
+		if champion, ok := current.Champion(); ok {
+			heading = fmt.Sprintf("%s - won by %s!", current.Name, champion.Name)
+			playAIButton.Disable()
+		}
+		view.Add(widget.NewLabelWithStyle(heading, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+
+		standings := widget.NewLabel(standingsText(current))
+		standings.TextStyle = fyne.TextStyle{Monospace: true}
+		view.Add(standings)
+
+		pending := make(map[*tournament.Match]bool)
+		for _, m := range current.Pending() {
+			pending[m] = true
+		}
+		for round := 1; round <= current.CurrentRound(); round++ {
+			view.Add(widget.NewLabelWithStyle(fmt.Sprintf("Round %d", round), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+			for _, m := range current.Matches {
+				if m.Round != round {
+					continue
+				}
+				line := widget.NewLabel(matchText(current, m))
+				if !pending[m] {
+					view.Add(line)
+					continue
+				}
+				m := m
+				playButton := widget.NewButton("Play", func() { play(m) })
+				if playing[m] {
+					playButton.Disable()
+				}
+				view.Add(container.NewHBox(line, playButton))
+			}
+		}
+	}
+
+	// Setting up a new tournament
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText("Office Tournament")
+	formatSelect := widget.NewSelect([]string{"Round Robin", "Knockout", "Swiss"}, nil)
+	formatSelect.SetSelected("Round Robin")
+	bestOfEntry := widget.NewEntry()
+	bestOfEntry.SetText("3")
+	swissRoundsEntry := widget.NewEntry()
+	swissRoundsEntry.SetPlaceHolder("Swiss rounds (blank for automatic)")
+	entrantsEntry := widget.NewMultiLineEntry()
+	entrantsEntry.SetPlaceHolder("One entrant per line, in seeding order:\nAlice\nBob\nHard AI: hard")
+	entrantsEntry.SetMinRowsVisible(6)
+
+	start := func() {
+		entrants, err := parseEntrants(entrantsEntry.Text)
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+		config := currentConfig()
+		if config.BestOf, err = strconv.Atoi(bestOfEntry.Text); err != nil {
+			statusLabel.SetText("Best of must be a number")
+			return
+		}
+		swissRounds := 0
+		if strings.TrimSpace(swissRoundsEntry.Text) != "" {
+			if swissRounds, err = strconv.Atoi(swissRoundsEntry.Text); err != nil || swissRounds < 1 {
+				statusLabel.SetText("Swiss rounds must be a number above zero")
+				return
+			}
+		}
+		t, err := tournament.New(nameEntry.Text, tournamentFormats[formatSelect.Selected], config, entrants, swissRounds)
+		if err != nil {
+			statusLabel.SetText("Could not start the tournament: " + err.Error())
+			return
+		}
+		current = t
+		statusLabel.SetText("")
+		save()
+		refresh()
+	}
+	startButton := widget.NewButton("Start Tournament with Setup Rules", func() {
+		if current != nil && !current.Finished() {
+			dialog.ShowConfirm("Start Tournament", "Abandon "+current.Name+" and start a new tournament?", func(ok bool) {
+				if ok {
+					start()
+				}
+			}, parent)
+			return
+		}
+		start()
+	})
+
+	setup := container.NewVBox(
+		widget.NewLabel("Name:"), nameEntry,
+		widget.NewLabel("Format:"), formatSelect,
+		widget.NewLabel("Best of:"), bestOfEntry,
+		swissRoundsEntry,
+		widget.NewLabel("Entrants (add \": easy\", \": medium\", \": hard\", \": neural\", \": adaptive\" or \": engine\" for an AI):"), entrantsEntry,
+		startButton,
+	)
+
+	refresh()
+	return container.NewBorder(
+		nil,
+		container.NewVBox(statusLabel, widget.NewAccordion(widget.NewAccordionItem("New Tournament", setup))),
+		nil, nil,
+		container.NewVScroll(container.NewVBox(playAIButton, view)),
+	)
+}
+
+// parseEntrants reads one entrant per line as "Name" for a person or
+// "Name: level" for an AI.
+func parseEntrants(text string) ([]tournament.Entrant, error) {
+	var entrants []tournament.Entrant
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, level, isAI := strings.Cut(line, ":")
+		entrant := tournament.Entrant{Name: strings.TrimSpace(name), PlayerType: types.HumanPlayer}
+		if isAI {
+			playerType, err := types.PlayerType(level)
+			if err != nil || !types.IsAI(playerType) {
+				return nil, fmt.Errorf("%s: %q is not an AI level", entrant.Name, strings.TrimSpace(level))
+			}
+			entrant.PlayerType = playerType
+		}
+		entrants = append(entrants, entrant)
+	}
+	return entrants, nil
+}
+
+// standingsText lays out the standings as a table
+func standingsText(t *tournament.Tournament) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-3s %-20s %3s %3s %3s %3s %5s", "#", "Name", "P", "W", "D", "L", "Pts")
+	if t.Format == tournament.Swiss {
+		fmt.Fprintf(&b, " %5s", "Buch")
+	}
+	b.WriteString("  Rounds\n")
+	for i, s := range t.Standings() {
+		name := t.Entrants[s.Entrant].Name
+		if s.Out {
+			name += " (out)"
+		}
+		fmt.Fprintf(&b, "%-3d %-20.20s %3d %3d %3d %3d %5.1f", i+1, name, s.Played, s.Won, s.Drawn, s.Lost, s.Points)
+		if t.Format == tournament.Swiss {
+			fmt.Fprintf(&b, " %5.1f", s.Buchholz)
+		}
+		fmt.Fprintf(&b, "  %d-%d\n", s.RoundsWon, s.RoundsLost)
+	}
+	return b.String()
+}
+
+// matchText describes a match and its score so far
+func matchText(t *tournament.Tournament, m *tournament.Match) string {
+	home := t.Entrants[m.Home].Name
+	if m.Away == tournament.Bye {
+		return home + " has a bye"
+	}
+	away := t.Entrants[m.Away].Name
+	if len(m.Winners) == 0 {
+		return home + " v " + away
+	}
+	homeScore, awayScore := m.Score()
+	text := fmt.Sprintf("%s %d-%d %s", home, homeScore, awayScore, away)
+	if winner, done := t.Result(m); !done {
+		text += " (level, sudden death next)"
+	} else if homeScore == awayScore && winner >= 0 {
+		text += " (level, " + t.Entrants[winner].Name + " through on seeding)"
+	}
+	return text
+}
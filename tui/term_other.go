@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+// makeRaw isn't available here, so keys are read a line at a time.
+func makeRaw(f *os.File) (func(), error) {
+	return nil, errors.New("single key presses aren't supported on this system")
+}
@@ -0,0 +1,334 @@
+// Package tui plays Connectron in a terminal, for machines without a display.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// aiMoveDelay gives the player a moment to see each AI move
+const aiMoveDelay = 400 * time.Millisecond
+
+// Keys, after escape sequences have been decoded
+const (
+	keyLeft  = -1
+	keyRight = -2
+	keyEnter = '\r'
+	keyQuit  = 'q'
+	keyBomb  = 'b'
+	ctrlC    = 3
+)
+
+// ErrQuit is returned by Run when the player quits before the series ends.
+var ErrQuit = errors.New("game abandoned")
+
+// terminalGame is a game being played in the terminal.
+type terminalGame struct {
+	gw     *ui.Game
+	out    io.Writer
+	raw    bool   // single key presses, rather than whole lines
+	cursor int    // the column a drop goes in
+	typed  string // column number being typed
+	bomb   bool   // the next drop is a bomb
+	info   string // what the game window's info label would say
+}
+
+// Run plays a game in the terminal until the series ends or the player
+// quits. People take turns at the keyboard; AI seats play themselves.
+func Run(config types.GameConfig, in *os.File, out io.Writer) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	return Play(ui.NewGameFromConfig(config), in, out)
+}
+
+// Play carries on a game that is already under way, such as a saved one.
+func Play(gw *ui.Game, in *os.File, out io.Writer) error {
+	for i, playerType := range gw.PlayerTypes[:gw.Players] {
+		if playerType == types.RemotePlayer {
+			return fmt.Errorf("player %d is remote, which the terminal version doesn't support", i+1)
+		}
+	}
+
+	defer gw.CloseEngines()
+
+	t := &terminalGame{gw: gw, out: out}
+	if restore, err := makeRaw(in); err == nil {
+		t.raw = true
+		defer restore()
+	}
+	fmt.Fprint(out, "\x1b[?25l")                  // hide the cursor
+	defer fmt.Fprint(out, "\x1b[0m\x1b[?25h\r\n") // and put everything back
+	return t.run(readKeys(in))
+}
+
+func (t *terminalGame) run(keys <-chan int) error {
+	if t.gw.RoundOver && !t.gw.SeriesOver() {
+		t.gw = t.gw.NextRound() // saved just as a round finished
+	}
+	for {
+		t.info = t.turnText()
+		if err := t.playRound(keys); err != nil {
+			return err
+		}
+		if t.gw.SeriesOver() {
+			t.info = t.resultText() + " " + seriesText(t.gw.Winners) + " Press any key to finish."
+			t.render()
+			<-keys
+			return nil
+		}
+		t.info = t.resultText() + " Press any key for the next round."
+		t.render()
+		if key, ok := <-keys; !ok || key == keyQuit || key == ctrlC {
+			return ErrQuit
+		}
+		t.gw = t.gw.NextRound()
+		t.cursor, t.typed, t.bomb = 0, "", false
+	}
+}
+
+// playRound takes moves until the round is over.
+func (t *terminalGame) playRound(keys <-chan int) error {
+	for !t.gw.RoundOver {
+		t.render()
+		playerType := t.gw.PlayerTypes[t.gw.CurrentTurn]
+		if types.IsAI(playerType) {
+			time.Sleep(aiMoveDelay)
+			t.play(t.gw.AIMove(playerType))
+			continue
+		}
+
+		key, ok := <-keys
+		if !ok || key == keyQuit || key == ctrlC {
+			return ErrQuit
+		}
+		t.handleKey(key)
+	}
+	t.cursor = 0
+	return nil
+}
+
+func (t *terminalGame) handleKey(key int) {
+	width := len(t.gw.Grid[0])
+	switch {
+	case key == keyLeft:
+		t.typed = ""
+		t.cursor = (t.cursor + width - 1) % width
+	case key == keyRight:
+		t.typed = ""
+		t.cursor = (t.cursor + 1) % width
+	case key >= '0' && key <= '9':
+		t.typed += string(rune(key))
+		if col, err := strconv.Atoi(t.typed); err == nil && col >= 1 && col <= width {
+			t.cursor = col - 1
+		} else {
+			t.typed = string(rune(key)) // start a new number
+			if col := key - '0'; col >= 1 && col <= width {
+				t.cursor = col - 1
+			}
+		}
+		// Jump straight to a column when no longer number could start with it
+		if col, _ := strconv.Atoi(t.typed); col*10 > width && t.raw {
+			t.drop()
+		}
+	case key == keyBomb:
+		if !t.gw.BombCounter {
+			t.info = "Bomb counters are not enabled!"
+			return
+		}
+		t.bomb = !t.bomb
+		t.info = t.turnText()
+	case key == keyEnter || key == ' ' || key == '\n':
+		t.drop()
+	}
+}
+
+func (t *terminalGame) drop() {
+	t.typed = ""
+	t.play(types.Move{Player: t.gw.CurrentTurn, Column: t.cursor, Bomb: t.bomb})
+}
+
+// play makes a move through the rules engine, just like the game window.
+func (t *terminalGame) play(move types.Move) {
+	result, err := t.gw.PlayMove(move)
+	if err != nil {
+		t.info = errorText(err)
+		return
+	}
+	t.bomb = false
+	t.info = t.turnText()
+	if result.Bomb {
+		t.info = fmt.Sprintf("Player %d dropped a bomb! ", result.Player+1) + t.info
+	}
+}
+
+func (t *terminalGame) turnText() string {
+	text := fmt.Sprintf("Player %d's Turn", t.gw.CurrentTurn+1)
+	if types.IsAI(t.gw.PlayerTypes[t.gw.CurrentTurn]) {
+		text += " (AI thinking...)"
+	}
+	if err := t.gw.EngineError(t.gw.CurrentTurn); err != nil {
+		text += " - medium AI playing, engine stopped: " + err.Error()
+	}
+	if t.bomb {
+		text += " - bomb armed!"
+	}
+	return text
+}
+
+func (t *terminalGame) resultText() string {
+	winner := t.gw.Winners[len(t.gw.Winners)-1]
+	if winner == 0 {
+		return "The game is a draw!"
+	}
+	return fmt.Sprintf("Player %d Wins!", winner)
+}
+
+// seriesText sums up the rounds won by each player
+func seriesText(winners []int) string {
+	wins := make(map[int]int)
+	for _, winner := range winners {
+		wins[winner]++
+	}
+	var parts []string
+	for player := 1; player <= types.MaxPlayers; player++ {
+		if wins[player] > 0 {
+			parts = append(parts, fmt.Sprintf("Player %d: %d", player, wins[player]))
+		}
+	}
+	if wins[0] > 0 {
+		parts = append(parts, fmt.Sprintf("draws: %d", wins[0]))
+	}
+	return "Rounds won - " + strings.Join(parts, ", ") + "."
+}
+
+func errorText(err error) string {
+	text := err.Error()
+	return strings.ToUpper(text[:1]) + text[1:] + "!"
+}
+
+// render redraws the whole screen. Lines end in \r\n as raw mode doesn't
+// return the carriage by itself.
+func (t *terminalGame) render() {
+	gw := t.gw
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "Connectron - Round %d of %d, %d in a row to win\r\n", gw.RoundCount+1, gw.BestOf, gw.WinLength)
+	if len(gw.Winners) > 0 {
+		b.WriteString(seriesText(gw.Winners) + "\r\n")
+	}
+	b.WriteString("\r\n")
+
+	// Which column the next counter goes in
+	b.WriteString(strings.Repeat("  ", t.cursor) + " v\r\n")
+	for _, row := range gw.Grid {
+		for _, cell := range row {
+			if cell < 0 {
+				b.WriteString(" \x1b[90m.\x1b[0m")
+				continue
+			}
+			c := gw.Colors[cell]
+			fmt.Fprintf(&b, " \x1b[38;2;%d;%d;%dmO\x1b[0m", c.R, c.G, c.B)
+		}
+		b.WriteString("\r\n")
+	}
+
+	// Column numbers, with a tens row above the units on wide boards
+	width := len(gw.Grid[0])
+	if width > 9 {
+		for col := 1; col <= width; col++ {
+			if col >= 10 && col%10 == 0 {
+				fmt.Fprintf(&b, " %d", col/10%10)
+			} else {
+				b.WriteString("  ")
+			}
+		}
+		b.WriteString("\r\n")
+	}
+	for col := 1; col <= width; col++ {
+		fmt.Fprintf(&b, " %d", col%10)
+	}
+	b.WriteString("\r\n\r\n")
+
+	for player := 0; player < gw.Players; player++ {
+		c := gw.Colors[player]
+		fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dmO\x1b[0m Player %d (%s)", c.R, c.G, c.B, player+1, playerTypeName(gw.PlayerTypes[player]))
+		if gw.BombCounter && gw.BombCounters[player] {
+			b.WriteString(" - bomb used")
+		}
+		b.WriteString("\r\n")
+	}
+	b.WriteString("\r\n" + t.info + "\r\n")
+	if t.typed != "" {
+		b.WriteString("Column: " + t.typed + "\r\n")
+	}
+	if t.raw {
+		b.WriteString("\x1b[90mLeft/Right or a number to choose, Enter to drop, b for a bomb, q to quit\x1b[0m\r\n")
+	} else {
+		b.WriteString("\x1b[90mType a column number and press Enter (b first for a bomb, q to quit)\x1b[0m\r\n")
+	}
+	io.WriteString(t.out, b.String())
+}
+
+func playerTypeName(playerType int) string {
+	switch playerType {
+	case types.HumanPlayer:
+		return "person"
+	case types.EasyAI:
+		return "easy AI"
+	case types.MediumAI:
+		return "medium AI"
+	case types.HardAI:
+		return "hard AI"
+	case types.ExternalEngine:
+		return "external engine"
+	case types.NeuralAI:
+		return "neural AI"
+	case types.AdaptiveAI:
+		return "adaptive AI"
+	}
+	return "unknown"
+}
+
+// readKeys turns what is typed into keys, decoding the arrow keys'
+// escape sequences. The channel is closed when input ends.
+func readKeys(in io.Reader) <-chan int {
+	keys := make(chan int)
+	go func() {
+		defer close(keys)
+		r := bufio.NewReader(in)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			key := int(b)
+			if b == 0x1b && r.Buffered() >= 2 {
+				seq := make([]byte, 2)
+				io.ReadFull(r, seq)
+				switch string(seq) {
+				case "[D":
+					key = keyLeft
+				case "[C":
+					key = keyRight
+				default:
+					continue
+				}
+			}
+			if key >= 'A' && key <= 'Z' {
+				key += 'a' - 'A'
+			}
+			keys <- key
+		}
+	}()
+	return keys
+}
@@ -0,0 +1,185 @@
+// Package tune improves the hard AI's weights by self-play, using SPSA
+// (simultaneous perturbation stochastic approximation): each step nudges
+// every weight at once up or down at random, plays the two resulting sets
+// of weights against each other, and moves towards whichever did better.
+package tune
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"insighthub.uk/connectron/v2/types"
+	"insighthub.uk/connectron/v2/ui"
+)
+
+// Games start with a few random moves, so the same two sets of weights
+// don't play the same game every time. Games still going after
+// maxMovesPerCell moves a cell are counted as draws.
+const (
+	openingMoves    = 2
+	maxMovesPerCell = 4
+)
+
+// The usual SPSA step sizes: step k moves weights by about a/(k+1+A)^0.602
+// of their size and tries them c/(k+1)^0.101 of their size either side.
+const (
+	stepA     = 0.1
+	stepC     = 0.15
+	stepAlpha = 0.602
+	stepGamma = 0.101
+)
+
+// Errors returned by Run for options it can't tune with.
+var (
+	ErrTooFewPlayers = errors.New("tuning needs at least two players")
+	ErrTooFewGames   = errors.New("each step needs at least two games, so both sets of weights move first")
+)
+
+// Options say what to tune for.
+type Options struct {
+	Config     types.GameConfig // the kind of game; every seat is played by the hard AI
+	Start      ui.Weights
+	Iterations int
+	Games      int   // games played between the two sets of weights each step
+	Validation int   // games between the tuned and starting weights at the end
+	Workers    int   // games played at once; 0 for one per CPU
+	Seed       int64 // the same seed tunes the same weights
+
+	// Progress, if set, is called after every step.
+	Progress func(iteration int, w ui.Weights)
+}
+
+// Result is what tuning came up with.
+type Result struct {
+	Weights ui.Weights
+	Score   float64 // share of the points the tuned weights won against Start
+}
+
+// Run tunes the weights.
+func Run(opts Options) (Result, error) {
+	config := opts.Config
+	config.BestOf = 1
+	config.PlayerTypes = make([]int, config.PlayerCount)
+	for i := range config.PlayerTypes {
+		config.PlayerTypes[i] = types.HardAI
+	}
+	if err := config.Validate(); err != nil {
+		return Result{}, err
+	}
+	if config.PlayerCount < 2 {
+		return Result{}, ErrTooFewPlayers
+	}
+	if opts.Games < 2 || opts.Validation != 0 && opts.Validation < 2 {
+		return Result{}, ErrTooFewGames
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	theta := opts.Start.Values()
+	// Steps are relative to each weight's starting size, so large and
+	// small weights move alike
+	scale := make([]float64, len(theta))
+	for i, value := range theta {
+		scale[i] = max(math.Abs(value), 1)
+	}
+	seed := opts.Seed
+
+	for k := 0; k < opts.Iterations; k++ {
+		a := stepA / math.Pow(float64(k+1+opts.Iterations/10), stepAlpha)
+		c := stepC / math.Pow(float64(k+1), stepGamma)
+		delta := make([]float64, len(theta))
+		plus := make([]float64, len(theta))
+		minus := make([]float64, len(theta))
+		for i := range theta {
+			delta[i] = float64(2*rng.Intn(2) - 1)
+			plus[i] = theta[i] + c*scale[i]*delta[i]
+			minus[i] = theta[i] - c*scale[i]*delta[i]
+		}
+
+		score := playMatch(config, ui.WeightsFromValues(plus), ui.WeightsFromValues(minus), opts.Games, opts.Workers, seed)
+		seed += int64(opts.Games)
+		// score is 0.5 when they are level; the gradient points towards
+		// whichever side won more
+		for i := range theta {
+			theta[i] += a * scale[i] * (score - 0.5) / (c * delta[i])
+			theta[i] = max(theta[i], 0)
+		}
+		if opts.Progress != nil {
+			opts.Progress(k+1, ui.WeightsFromValues(theta))
+		}
+	}
+
+	result := Result{Weights: ui.WeightsFromValues(theta), Score: 0.5}
+	if opts.Validation > 0 {
+		result.Score = playMatch(config, result.Weights, opts.Start, opts.Validation, opts.Workers, seed)
+	}
+	return result, nil
+}
+
+// playMatch plays games between two sets of weights, taking turns at which
+// has the odd seats, and returns the share of the points a won.
+func playMatch(config types.GameConfig, a, b ui.Weights, games, workers int, seed int64) float64 {
+	points := make([]float64, games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, games); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				points[i] = playGame(config, a, b, i%2, seed+int64(i))
+			}
+		}()
+	}
+	for i := range points {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	total := 0.0
+	for _, p := range points {
+		total += p
+	}
+	return total / float64(games)
+}
+
+// playGame plays one round with a in the seats of the given parity and b in
+// the rest, and returns a's points: 1 for a win, a half for a draw.
+func playGame(config types.GameConfig, a, b ui.Weights, parity int, seed int64) float64 {
+	gw := ui.NewGameFromConfig(config)
+	gw.SetSeed(seed)
+	for seat := 0; seat < config.PlayerCount; seat++ {
+		if seat%2 == parity {
+			gw.SetWeights(seat, a)
+		} else {
+			gw.SetWeights(seat, b)
+		}
+	}
+
+	limit := maxMovesPerCell * config.GridWidth * config.GridHeight
+	for moves := 0; !gw.RoundOver; moves++ {
+		if moves == limit {
+			return 0.5
+		}
+		level := types.HardAI
+		if moves < openingMoves {
+			level = types.EasyAI
+		}
+		if _, err := gw.PlayMove(gw.AIMove(level)); err != nil {
+			return 0.5
+		}
+	}
+	switch winner := gw.Winners[0]; {
+	case winner == 0:
+		return 0.5
+	case (winner-1)%2 == parity:
+		return 1
+	}
+	return 0
+}
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
 type GameWindow struct {
 	GridWidth   int
 	GridHeight  int
@@ -7,4 +12,206 @@ type GameWindow struct {
 	PlayerCount int
 	IncludeAI   bool
 	Alliances   bool
-}
\ No newline at end of file
+}
+
+// Player types stored in Game.PlayerTypes. AI levels are 0 and above.
+const (
+	EasyAI = iota
+	MediumAI
+	HardAI
+	ExternalEngine // a program of the user's own, see package engine
+	NeuralAI       // searches like HardAI, scoring positions with a trained network
+	AdaptiveAI     // plays up or down to match the people it is playing
+)
+
+const (
+	HumanPlayer  = -1 // a person at this computer
+	RemotePlayer = -2 // a person connected over the network
+)
+
+// playerTypeNames maps the names used on the command line, in files and
+// over the API to player types
+var playerTypeNames = map[string]int{
+	"easy":     EasyAI,
+	"medium":   MediumAI,
+	"hard":     HardAI,
+	"engine":   ExternalEngine,
+	"neural":   NeuralAI,
+	"adaptive": AdaptiveAI,
+	"person":   HumanPlayer,
+	"remote":   RemotePlayer,
+}
+
+// PlayerTypeName is the name given to a player type.
+func PlayerTypeName(playerType int) string {
+	for name, t := range playerTypeNames {
+		if t == playerType {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// PlayerType looks up a player type by the name PlayerTypeName gives it.
+func PlayerType(name string) (int, error) {
+	playerType, ok := playerTypeNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown player type %q", name)
+	}
+	return playerType, nil
+}
+
+// Personalities are the play styles an AI seat can have, by the names
+// stored in GameConfig.Personalities. The first is the usual one.
+var Personalities = []string{"balanced", "aggressive", "defensive", "centre", "corner", "bombs", "loyal", "opportunist"}
+
+// GameConfig holds everything picked on the setup screen that is needed to build a Game.
+type GameConfig struct {
+	GridWidth       int        `json:"gridWidth"`
+	GridHeight      int        `json:"gridHeight"`
+	LineLength      int        `json:"lineLength"`
+	PlayerCount     int        `json:"playerCount"`
+	BestOf          int        `json:"bestOf"`
+	PlayerTypes     []int      `json:"playerTypes"`
+	AIForMissing    bool       `json:"aiForMissing"`
+	CornerBonus     bool       `json:"cornerBonus"`
+	SolitaireRule   bool       `json:"solitaireRule"`
+	BombCounter     bool       `json:"bombCounter"`
+	OverflowRule    bool       `json:"overflowRule"`
+	EnableAlliances bool       `json:"enableAlliances"`
+	Alliances       [][]string `json:"alliances,omitempty"`
+	Personalities   []string   `json:"personalities,omitempty"` // each seat's AI play style; "" or missing for balanced
+	HintBudget      int        `json:"hintBudget,omitempty"`    // hints each person may take a game, over every round
+}
+
+// Move is a single counter drop made by a player.
+type Move struct {
+	Player int  `json:"player"`
+	Column int  `json:"column"`
+	Bomb   bool `json:"bomb,omitempty"`
+	Hinted bool `json:"hinted,omitempty"` // the player took a hint before making it
+}
+
+// GameState is a snapshot of a game in progress, enough to redraw the board.
+type GameState struct {
+	Grid         [][]int `json:"grid"`
+	CurrentTurn  int     `json:"currentTurn"`
+	RoundCount   int     `json:"roundCount"`
+	Winners      []int   `json:"winners,omitempty"`
+	BombCounters []bool  `json:"bombCounters"`
+	LastMove     *Move   `json:"lastMove,omitempty"`
+	RoundOver    bool    `json:"roundOver,omitempty"`
+	SeriesOver   bool    `json:"seriesOver,omitempty"`
+	Spectators   int     `json:"spectators,omitempty"` // filled in by the host
+}
+
+// MaxChatLength is the longest chat message, in characters.
+const MaxChatLength = 200
+
+// ChatMessage is something said during a networked game. It is kept in the
+// game's log along with the moves so replays can show it at the right time.
+type ChatMessage struct {
+	Round    int    `json:"round"`  // the round it was said in
+	Move     int    `json:"move"`   // how many moves into the round
+	Player   int    `json:"player"` // the seat that said it
+	Name     string `json:"name,omitempty"`
+	Text     string `json:"text"`
+	Alliance bool   `json:"alliance,omitempty"` // only for the player's alliance
+}
+
+// Allied reports whether two players are in the same alliance. Players are
+// counted from 0, as in the grid.
+func (c GameConfig) Allied(player1, player2 int) bool {
+	if !c.EnableAlliances {
+		return false
+	}
+	for _, alliance := range c.Alliances {
+		in1, in2 := false, false
+		for _, p := range alliance {
+			in1 = in1 || p == fmt.Sprintf("Player-%d", player1+1)
+			in2 = in2 || p == fmt.Sprintf("Player-%d", player2+1)
+		}
+		if in1 && in2 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPersonality reports whether name is one of the Personalities.
+func IsPersonality(name string) bool {
+	for _, personality := range Personalities {
+		if personality == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAI reports whether a player type is played by the computer: one of the
+// AI levels or an external engine.
+func IsAI(playerType int) bool {
+	return playerType >= EasyAI
+}
+
+// Limits on the game settings, the same ranges the setup screen's sliders allow.
+const (
+	MinGridSize   = 6
+	MaxGridSize   = 100
+	MinLineLength = 4
+	MaxLineLength = 10
+	MinPlayers    = 1
+	MaxPlayers    = 10
+)
+
+// Validate checks the settings are ones the setup screen could have produced.
+func (c GameConfig) Validate() error {
+	if c.GridWidth < MinGridSize || c.GridWidth > MaxGridSize {
+		return fmt.Errorf("grid width must be %d-%d, got %d", MinGridSize, MaxGridSize, c.GridWidth)
+	}
+	if c.GridHeight < MinGridSize || c.GridHeight > MaxGridSize {
+		return fmt.Errorf("grid height must be %d-%d, got %d", MinGridSize, MaxGridSize, c.GridHeight)
+	}
+	if c.LineLength < MinLineLength || c.LineLength > MaxLineLength {
+		return fmt.Errorf("line length must be %d-%d, got %d", MinLineLength, MaxLineLength, c.LineLength)
+	}
+	if c.PlayerCount < MinPlayers || c.PlayerCount > MaxPlayers {
+		return fmt.Errorf("number of players must be %d-%d, got %d", MinPlayers, MaxPlayers, c.PlayerCount)
+	}
+	if c.BestOf < 1 || c.BestOf%2 == 0 {
+		return fmt.Errorf("best of must be an odd number of rounds (1, 3, 5...), got %d", c.BestOf)
+	}
+	if len(c.PlayerTypes) < c.PlayerCount {
+		return fmt.Errorf("%d player types given for %d players", len(c.PlayerTypes), c.PlayerCount)
+	}
+	for i, playerType := range c.PlayerTypes[:c.PlayerCount] {
+		if playerType < RemotePlayer || playerType > AdaptiveAI {
+			return fmt.Errorf("player %d has unknown type %d", i+1, playerType)
+		}
+	}
+	if c.HintBudget < 0 {
+		return fmt.Errorf("hint budget can't be negative, got %d", c.HintBudget)
+	}
+	if len(c.Personalities) > c.PlayerCount {
+		return fmt.Errorf("%d personalities given for %d players", len(c.Personalities), c.PlayerCount)
+	}
+	for i, personality := range c.Personalities {
+		if personality != "" && !IsPersonality(personality) {
+			return fmt.Errorf("player %d has unknown personality %q", i+1, personality)
+		}
+	}
+	allied := make(map[string]bool)
+	for _, alliance := range c.Alliances {
+		for _, player := range alliance {
+			var number int
+			if _, err := fmt.Sscanf(player, "Player-%d", &number); err != nil || number < 1 || number > c.PlayerCount {
+				return fmt.Errorf("alliances include %q, but there are only %d players", player, c.PlayerCount)
+			}
+			if allied[player] {
+				return fmt.Errorf("%s is in more than one alliance", player)
+			}
+			allied[player] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// AdaptivePath is where the adaptive AI keeps how each person has been
+// doing against it.
+var AdaptivePath = filepath.Join("files", "adaptive.json")
+
+// The adaptive AI's level runs from 0, which looks one move ahead and often
+// blunders, to MaxAdaptiveLevel, which plays like the hard AI.
+const (
+	MaxAdaptiveLevel = 10
+	adaptiveHistory  = 10  // rounds remembered for each person
+	maxMistakeChance = 0.3 // how often it blunders on purpose at level 0
+	maxTolerance     = 40  // how much worse than its best a move it picks may be at level 0
+	qualityDepth     = 2   // how far ahead people's moves are judged
+)
+
+// AdaptiveRecord is how a person has been doing against the adaptive AI.
+type AdaptiveRecord struct {
+	Level   float64   `json:"level"`
+	Results []float64 `json:"results"` // recent rounds, newest last: 1 won, 0.5 drawn, 0 lost
+	Quality []float64 `json:"quality"` // their average move quality in each of those rounds, from 0 to 1
+}
+
+// adaptiveFile is the layout of the adaptive AI's file, by leaderboard name.
+type adaptiveFile struct {
+	Version int                        `json:"version"`
+	Players map[string]*AdaptiveRecord `json:"players"`
+}
+
+// adaptiveState follows the people in a series with an adaptive AI in it.
+type adaptiveState struct {
+	mu      sync.Mutex
+	players map[int]string // each person's seat and leaderboard name
+	records map[string]*AdaptiveRecord
+	started map[string]float64   // levels when the series began
+	quality map[string][]float64 // how good each move of theirs was this round
+}
+
+// adaptiveState returns the series' adaptive AI state, starting it the
+// first time it's needed. It is nil unless an adaptive AI is playing people.
+func (g *Game) adaptiveState() *adaptiveState {
+	if g.adaptive != nil || g.simulated {
+		return g.adaptive
+	}
+	players := make(map[int]string)
+	adaptive := false
+	for seat, playerType := range g.PlayerTypes[:g.Players] {
+		switch playerType {
+		case types.AdaptiveAI:
+			adaptive = true
+		case types.HumanPlayer, types.RemotePlayer:
+			players[seat] = fmt.Sprintf("Player-%d", seat+1) // as on the leaderboard
+		}
+	}
+	if !adaptive || len(players) == 0 {
+		return nil
+	}
+
+	st := &adaptiveState{
+		players: players,
+		records: readAdaptiveRecords(),
+		started: make(map[string]float64),
+		quality: make(map[string][]float64),
+	}
+	for _, name := range players {
+		if st.records[name] == nil {
+			st.records[name] = &AdaptiveRecord{Level: leaderboardLevel(name)}
+		}
+		st.started[name] = st.records[name].Level
+	}
+	g.adaptive = st
+	return st
+}
+
+// AdaptiveLevel is the level the adaptive AI plays at: the average of the
+// people it is playing, or the middle level if there are none.
+func (g *Game) AdaptiveLevel() float64 {
+	st := g.adaptiveState()
+	if st == nil {
+		return MaxAdaptiveLevel / 2
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	total := 0.0
+	for _, name := range st.players {
+		total += st.records[name].Level
+	}
+	return total / float64(len(st.players))
+}
+
+// AdaptiveSummary describes how the adaptive AI's level has changed over
+// the series for each person, or is "" if no adaptive AI was playing.
+func (g *Game) AdaptiveSummary() string {
+	st := g.adaptiveState()
+	if st == nil {
+		return ""
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var lines []string
+	for seat := 0; seat < g.Players; seat++ {
+		name, ok := st.players[seat]
+		if !ok {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("Adaptive AI level for Player %d: %.1f of %d (started at %.1f)",
+			seat+1, st.records[name].Level, MaxAdaptiveLevel, st.started[name]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// adaptiveMove - the hard AI's search, held back to the current level: it
+// looks less far ahead, picks at random among moves nearly as good as its
+// best, and now and then plays a worse move on purpose.
+func (g *Game) adaptiveMove() types.Move {
+	level := g.AdaptiveLevel()
+	weakness := 1 - level/MaxAdaptiveLevel
+	s := newSearcher(g)
+	s.limit = 1 + int(level*(maxSearchDepth-1)/MaxAdaptiveLevel)
+	s.bombs = level >= MaxAdaptiveLevel/2
+	moves, scores := s.scoreMoves()
+	if len(moves) == 0 {
+		return types.Move{Player: g.CurrentTurn, Column: -1}
+	}
+	best := 0
+	for i, score := range scores {
+		if score > scores[best] {
+			best = i
+		}
+	}
+
+	if len(moves) > 1 && g.randomFloat() < maxMistakeChance*weakness {
+		pick := g.randomIntn(len(moves) - 1)
+		if pick >= best {
+			pick++
+		}
+		return moves[pick]
+	}
+	var near []types.Move
+	for i, score := range scores {
+		if score >= scores[best]-maxTolerance*weakness {
+			near = append(near, moves[i])
+		}
+	}
+	return near[g.randomIntn(len(near))]
+}
+
+// adaptiveAI is adaptiveMove for GetAIColumn, which only drops counters.
+func (g *Game) adaptiveAI() (int, int) {
+	move := g.adaptiveMove()
+	if move.Bomb {
+		return g.hardAI()
+	}
+	row, _ := g.DropCounter(move.Column)
+	return move.Column, row
+}
+
+// judgeMove rates a person's move, before it is played, by how many of
+// the other moves they could have made it is at least as good as.
+func (g *Game) judgeMove(move types.Move) {
+	st := g.adaptiveState()
+	if st == nil {
+		return
+	}
+	name, ok := st.players[g.CurrentTurn]
+	if !ok {
+		return
+	}
+	s := newSearcher(g)
+	s.limit = qualityDepth
+	s.bombs = true
+	moves, scores := s.scoreMoves()
+	chosen := -1
+	for i, m := range moves {
+		if m.Column == move.Column && m.Bomb == move.Bomb {
+			chosen = i
+		}
+	}
+	if chosen == -1 || len(moves) < 2 {
+		return
+	}
+	worse := 0
+	for _, score := range scores {
+		if score <= scores[chosen] {
+			worse++
+		}
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.quality[name] = append(st.quality[name], float64(worse-1)/float64(len(moves)-1))
+}
+
+// adaptRound moves each person's level on once a round is over: up when
+// they won or played well, down when they lost or played badly.
+func (g *Game) adaptRound(winner int) {
+	st := g.adaptiveState()
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sides := g.sides()
+	for seat, name := range st.players {
+		var result float64
+		switch {
+		case winner == 0:
+			result = 0.5
+		case sides[winner-1] == sides[seat]:
+			result = 1
+		case g.PlayerTypes[winner-1] == types.AdaptiveAI:
+			result = 0
+		default:
+			continue // someone else won; it says nothing about the AI's level
+		}
+		quality := 0.5
+		if moves := st.quality[name]; len(moves) > 0 {
+			quality = 0
+			for _, q := range moves {
+				quality += q
+			}
+			quality /= float64(len(moves))
+		}
+		st.quality[name] = nil
+
+		record := st.records[name]
+		record.Results = lastFew(append(record.Results, result))
+		record.Quality = lastFew(append(record.Quality, quality))
+		record.Level += 2*(result-0.5) + (quality - 0.5)
+		record.Level = min(max(record.Level, 0), MaxAdaptiveLevel)
+	}
+	if err := writeAdaptiveRecords(st.records); err != nil {
+		fmt.Println("Error saving adaptive AI levels:", err)
+	}
+}
+
+func lastFew(values []float64) []float64 {
+	if len(values) > adaptiveHistory {
+		return values[len(values)-adaptiveHistory:]
+	}
+	return values
+}
+
+// leaderboardLevel is where someone new to the adaptive AI starts: higher
+// the more of their games on the leaderboard they have won.
+func leaderboardLevel(name string) float64 {
+	records, _ := ReadLeaderboard()
+	for _, record := range records {
+		if len(record) > 4 && record[0] == name {
+			played, _ := strconv.Atoi(record[3])
+			won, _ := strconv.Atoi(record[4])
+			// Counting an extra win and loss keeps a few lucky games from
+			// going straight to the top
+			return MaxAdaptiveLevel * float64(won+1) / float64(played+2)
+		}
+	}
+	return MaxAdaptiveLevel / 2
+}
+
+func readAdaptiveRecords() map[string]*AdaptiveRecord {
+	records := make(map[string]*AdaptiveRecord)
+	data, err := os.ReadFile(AdaptivePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Println("Error reading adaptive AI levels:", err)
+		}
+		return records
+	}
+	var file adaptiveFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != 1 {
+		fmt.Println("Error reading adaptive AI levels: not an adaptive AI file")
+		return records
+	}
+	for name, record := range file.Players {
+		if record != nil {
+			records[name] = record
+		}
+	}
+	return records
+}
+
+// writeAdaptiveRecords saves this series' people, keeping everyone else
+// already in the file.
+func writeAdaptiveRecords(records map[string]*AdaptiveRecord) error {
+	all := readAdaptiveRecords()
+	for name, record := range records {
+		all[name] = record
+	}
+	data, err := json.MarshalIndent(adaptiveFile{Version: 1, Players: all}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(AdaptivePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(AdaptivePath, data, 0644)
+}
+
+func (g *Game) randomIntn(n int) int {
+	if g.rng != nil {
+		return g.rng.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+func (g *Game) randomFloat() float64 {
+	if g.rng != nil {
+		return g.rng.Float64()
+	}
+	return rand.Float64()
+}
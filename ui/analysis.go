@@ -0,0 +1,252 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"insighthub.uk/connectron/v2/saves"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Grade is how much a move threw away, by the AI's reckoning.
+type Grade int
+
+const (
+	GoodMove Grade = iota
+	Inaccuracy
+	Mistake
+	Blunder
+)
+
+func (g Grade) String() string {
+	switch g {
+	case Inaccuracy:
+		return "inaccuracy"
+	case Mistake:
+		return "mistake"
+	case Blunder:
+		return "blunder"
+	}
+	return "good"
+}
+
+// How far ahead every move is checked, and how much worse than the best
+// move, in evaluation points, a move has to score for each grade. A line a
+// counter short of winning is worth about 50.
+const (
+	analysisDepth  = 3
+	inaccuracyLoss = 20
+	mistakeLoss    = 60
+	blunderLoss    = 150
+	evalScale      = 150 // an evaluation three quarters of the way to a win on the graph
+)
+
+// MoveAnalysis is what the AI makes of one move.
+type MoveAnalysis struct {
+	Round  int // from 0
+	Number int // the move's number in its round, from 1
+	Move   types.Move
+	Best   types.Move // the move the AI would have made
+	Loss   float64    // how much worse than Best it scored, for the player who made it
+	Grade  Grade
+	Note   string  // what went wrong, such as a missed win
+	Eval   float64 // the position afterwards for Player 1's side, from -1 (lost) to 1 (won)
+}
+
+// Analysis is a whole game gone over move by move.
+type Analysis struct {
+	Config types.GameConfig
+	Moves  []MoveAnalysis
+}
+
+// Analyse replays a game through the AI, scoring every move against the
+// best one it can find and checking the rules as Replay does.
+func Analyse(record saves.GameRecord) (*Analysis, error) {
+	if err := record.Config.Validate(); err != nil {
+		return nil, err
+	}
+	a := &Analysis{Config: record.Config}
+	g := NewGameFromConfig(record.Config)
+	g.simulated = true // only going over it, so nothing is recorded
+	number := 0
+	lastBefore := 0.0
+	for i, move := range record.Moves {
+		if g.RoundOver {
+			if g.SeriesOver() {
+				return nil, fmt.Errorf("move %d: the game is already over", i+1)
+			}
+			g = g.NextRound()
+			g.simulated = true
+			number = 0
+		}
+		if move.Player != g.CurrentTurn {
+			return nil, fmt.Errorf("move %d: it was player %d's turn, not player %d's", i+1, g.CurrentTurn+1, move.Player+1)
+		}
+
+		number++
+		m, before := g.analyseMove(move)
+		m.Round, m.Number = g.RoundCount, number
+		// How things stand between two moves is judged from both sides, as
+		// each looking ahead favours whoever moves last in its search
+		if number > 1 {
+			a.Moves[len(a.Moves)-1].Eval = (lastBefore + before) / 2
+		}
+		lastBefore = before
+		result, err := g.PlayMove(move)
+		if err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		switch {
+		case result.Won && g.sides()[result.Player] == g.sides()[0]:
+			m.Eval = 1
+		case result.Won:
+			m.Eval = -1
+		}
+		a.Moves = append(a.Moves, m)
+	}
+
+	// A game saved part way through ends on a position still being played
+	if len(a.Moves) > 0 && !g.RoundOver {
+		if _, scores := g.analysisSearcher().scoreMoves(); len(scores) > 0 {
+			best := scores[0]
+			for _, score := range scores {
+				best = max(best, score)
+			}
+			a.Moves[len(a.Moves)-1].Eval = (lastBefore + g.firstSideEval(best)) / 2
+		}
+	}
+	return a, nil
+}
+
+// analysisSearcher looks ahead for the player to move, judging by the
+// usual weights whatever their seat's personality.
+func (g *Game) analysisSearcher() *searcher {
+	s := newSearcher(g)
+	s.w, s.loyalty, s.bombFirst = g.SeatWeights(s.me), 1, false
+	s.limit = analysisDepth
+	s.bombs = true
+	return s
+}
+
+// firstSideEval turns a score for the player to move into one for Player
+// 1's side, from -1 to 1.
+func (g *Game) firstSideEval(score float64) float64 {
+	if sides := g.sides(); sides[g.CurrentTurn] != sides[0] {
+		score = -score
+	}
+	return math.Tanh(score / evalScale)
+}
+
+// analyseMove scores a move before it is played. It also returns how the
+// position stands for Player 1's side, from -1 to 1.
+func (g *Game) analyseMove(move types.Move) (MoveAnalysis, float64) {
+	m := MoveAnalysis{Move: move, Best: move}
+	s := g.analysisSearcher()
+	moves, scores := s.scoreMoves()
+	best, chosen := -1, -1
+	for i, candidate := range moves {
+		if best == -1 || scores[i] > scores[best] {
+			best = i
+		}
+		if candidate.Column == move.Column && candidate.Bomb == move.Bomb {
+			chosen = i
+		}
+	}
+	if best == -1 || chosen == -1 {
+		return m, 0
+	}
+
+	before := g.firstSideEval(scores[best])
+	m.Best = moves[best]
+	m.Loss = scores[best] - scores[chosen]
+	switch {
+	case scores[best] >= winScore/2 && scores[chosen] < winScore/2:
+		m.Grade, m.Note = Blunder, "missed a forced win"
+	case scores[chosen] <= -winScore/2 && scores[best] > -winScore/2:
+		m.Grade, m.Note = Blunder, "lets an opponent force a win"
+	case m.Loss >= blunderLoss:
+		m.Grade = Blunder
+	case m.Loss >= mistakeLoss:
+		m.Grade = Mistake
+	case m.Loss >= inaccuracyLoss:
+		m.Grade = Inaccuracy
+	}
+	if m.Grade == GoodMove {
+		return m, before
+	}
+
+	// Say what went wrong, when it's plain to see
+	if wins := g.winningColumns(s.me); len(wins) > 0 {
+		m.Grade, m.Note = Blunder, fmt.Sprintf("missed a win in column %d", wins[0]+1)
+		return m, before
+	}
+	next, _, err := g.simulate(move)
+	if err != nil {
+		return m, before
+	}
+	sides := g.sides()
+	for player := 0; player < g.Players; player++ {
+		if sides[player] == sides[s.me] {
+			continue
+		}
+		if threats := next.winningColumns(player); len(threats) > 0 && len(g.winningColumns(player)) > 0 {
+			m.Note = fmt.Sprintf("didn't block Player %d in column %d", player+1, threats[0]+1)
+			return m, before
+		}
+	}
+	return m, before
+}
+
+// Counts is how many of each grade of move each player made.
+func (a *Analysis) Counts() [][Blunder + 1]int {
+	counts := make([][Blunder + 1]int, a.Config.PlayerCount)
+	for _, m := range a.Moves {
+		counts[m.Move.Player][m.Grade]++
+	}
+	return counts
+}
+
+// Describe is one line about a move, such as "12. Player 2, column 3 -
+// blunder: missed a win in column 5 (better: column 5)".
+func (m MoveAnalysis) Describe() string {
+	text := fmt.Sprintf("%d. Player %d, %s", m.Number, m.Move.Player+1, moveText(m.Move))
+	if m.Grade == GoodMove {
+		return text
+	}
+	text += " - " + m.Grade.String()
+	if m.Note != "" {
+		text += ": " + m.Note
+	}
+	return text + fmt.Sprintf(" (better: %s)", moveText(m.Best))
+}
+
+func moveText(move types.Move) string {
+	if move.Bomb {
+		return fmt.Sprintf("bomb in column %d", move.Column+1)
+	}
+	return fmt.Sprintf("column %d", move.Column+1)
+}
+
+// Report is the analysis as plain text, for saving.
+func (a *Analysis) Report() string {
+	var b strings.Builder
+	c := a.Config
+	fmt.Fprintf(&b, "Connectron game analysis\n%dx%d board, %d in a row, %d players, best of %d\n",
+		c.GridWidth, c.GridHeight, c.LineLength, c.PlayerCount, c.BestOf)
+	round := -1
+	for _, m := range a.Moves {
+		if m.Round != round {
+			round = m.Round
+			fmt.Fprintf(&b, "\nRound %d\n", round+1)
+		}
+		fmt.Fprintf(&b, "  %s\n", m.Describe())
+	}
+
+	b.WriteString("\nSummary\n")
+	for player, counts := range a.Counts() {
+		fmt.Fprintf(&b, "  Player %d: %d inaccuracies, %d mistakes, %d blunders\n",
+			player+1, counts[Inaccuracy], counts[Mistake], counts[Blunder])
+	}
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/saves"
+)
+
+// AnalysisWindow goes over a finished or saved game with the AI: the board
+// at any move, a graph of who was ahead, and every move it would have
+// played differently. The analysis can be saved as a report.
+func AnalysisWindow(record saves.GameRecord, connectronApp fyne.App) {
+	analysisWindow := connectronApp.NewWindow("Connectron - Analysis")
+	infoLabel := widget.NewLabel("Analysing the game...")
+	infoLabel.Wrapping = fyne.TextWrapWord
+	analysisWindow.SetContent(infoLabel)
+	analysisWindow.Resize(fyne.NewSize(900, 700))
+	analysisWindow.Show()
+
+	go func() {
+		analysis, err := Analyse(record)
+		if err != nil {
+			infoLabel.SetText("This game can't be analysed: " + err.Error())
+			return
+		}
+		analysisWindow.SetContent(analysisContent(record, analysis, analysisWindow))
+	}()
+}
+
+func analysisContent(record saves.GameRecord, analysis *Analysis, parent fyne.Window) fyne.CanvasObject {
+	infoLabel := widget.NewLabel("")
+	infoLabel.Wrapping = fyne.TextWrapWord
+	gw := NewGameFromConfig(record.Config)
+	gridContainer := newGridContainer(gw)
+	assist := newBoardAssist(gw, gridContainer)
+	graph := newEvalGraph(analysis)
+
+	slider := widget.NewSlider(0, float64(len(record.Moves)))
+	slider.Step = 1
+	show := func(moves int) {
+		game, err := Replay(record.Config, record.Moves[:moves])
+		if err != nil {
+			infoLabel.SetText("This game doesn't follow the rules: " + err.Error())
+			return
+		}
+		gw.ApplyState(game.State())
+		refreshGrid(gw, gridContainer)
+		assist.update(game)
+		graph.setPosition(moves)
+
+		if moves == 0 {
+			infoLabel.SetText("Start of the game")
+			return
+		}
+		m := analysis.Moves[moves-1]
+		infoLabel.SetText(fmt.Sprintf("Round %d, move %s", m.Round+1, m.Describe()))
+		if m.Grade != GoodMove {
+			highlightCell(gw, gridContainer, landingRow(gw.Grid, m.Best.Column), m.Best.Column)
+		}
+	}
+	slider.OnChanged = func(value float64) { show(int(value)) }
+
+	// Every move that could have been better, to jump to
+	var flagged []MoveAnalysis
+	var positions []int
+	for i, m := range analysis.Moves {
+		if m.Grade != GoodMove {
+			flagged = append(flagged, m)
+			positions = append(positions, i+1)
+		}
+	}
+	list := widget.NewList(
+		func() int { return len(flagged) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(fmt.Sprintf("Round %d, %s", flagged[id].Round+1, flagged[id].Describe()))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { slider.SetValue(float64(positions[id])) }
+
+	var summary []string
+	for player, counts := range analysis.Counts() {
+		summary = append(summary, fmt.Sprintf("Player %d: %d inaccuracies, %d mistakes, %d blunders",
+			player+1, counts[Inaccuracy], counts[Mistake], counts[Blunder]))
+	}
+
+	exportButton := widget.NewButton("Export Report", func() {
+		dialog.ShowFileSave(func(file fyne.URIWriteCloser, err error) {
+			if err != nil || file == nil {
+				return
+			}
+			file.Close()
+			if err := os.WriteFile(file.URI().Path(), []byte(analysis.Report()), 0644); err != nil {
+				dialog.ShowError(err, parent)
+			}
+		}, parent)
+	})
+
+	step := func(by int) {
+		moves := int(slider.Value) + by
+		if moves >= 0 && moves <= len(record.Moves) {
+			slider.SetValue(float64(moves))
+		}
+	}
+	controls := container.NewHBox(
+		widget.NewButton("|<", func() { slider.SetValue(0) }),
+		widget.NewButton("<", func() { step(-1) }),
+		widget.NewButton(">", func() { step(1) }),
+		widget.NewButton(">|", func() { slider.SetValue(float64(len(record.Moves))) }),
+		exportButton,
+	)
+
+	side := container.NewBorder(
+		container.NewVBox(widget.NewLabel(strings.Join(summary, "\n")), widget.NewLabel("Moves the AI would have played differently:")),
+		nil, nil, nil, list,
+	)
+	board := container.NewBorder(
+		container.NewVBox(infoLabel, slider, controls, assist.options, assist.bar.container),
+		container.NewVBox(widget.NewLabel("Who was ahead (Player 1's side up):"), graph.container),
+		nil, nil, gridContainer,
+	)
+	show(0)
+	return container.NewHSplit(board, side)
+}
+
+// evalGraph draws the analysis's evaluation after every move, with Player
+// 1's side winning at the top and a marker at the move being shown.
+type evalGraph struct {
+	values    []float64
+	container *fyne.Container
+	position  int
+}
+
+func newEvalGraph(analysis *Analysis) *evalGraph {
+	graph := &evalGraph{values: []float64{0}} // level at the start
+	for _, m := range analysis.Moves {
+		graph.values = append(graph.values, m.Eval)
+	}
+	objects := []fyne.CanvasObject{
+		canvas.NewRectangle(color.RGBA{245, 245, 245, 255}),
+		canvas.NewLine(color.RGBA{180, 180, 180, 255}), // level
+		canvas.NewLine(color.RGBA{0, 0, 255, 255}),     // the move being shown
+	}
+	for range graph.values[1:] {
+		line := canvas.NewLine(color.RGBA{0, 0, 0, 255})
+		line.StrokeWidth = 2
+		objects = append(objects, line)
+	}
+	graph.container = container.New(graph, objects...)
+	return graph
+}
+
+func (graph *evalGraph) setPosition(moves int) {
+	graph.position = moves
+	graph.container.Refresh()
+}
+
+// Layout places the graph's lines; evalGraph is its own layout.
+func (graph *evalGraph) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	point := func(i int) fyne.Position {
+		x := size.Width / 2
+		if len(graph.values) > 1 {
+			x = size.Width * float32(i) / float32(len(graph.values)-1)
+		}
+		return fyne.NewPos(x, size.Height/2*(1-float32(graph.values[i])))
+	}
+
+	objects[0].Resize(size)
+	objects[0].Move(fyne.NewPos(0, 0))
+	level := objects[1].(*canvas.Line)
+	level.Position1, level.Position2 = fyne.NewPos(0, size.Height/2), fyne.NewPos(size.Width, size.Height/2)
+	marker := objects[2].(*canvas.Line)
+	x := point(graph.position).X
+	marker.Position1, marker.Position2 = fyne.NewPos(x, 0), fyne.NewPos(x, size.Height)
+	for i, object := range objects[3:] {
+		line := object.(*canvas.Line)
+		line.Position1, line.Position2 = point(i), point(i+1)
+		line.Refresh()
+	}
+	level.Refresh()
+	marker.Refresh()
+}
+
+func (graph *evalGraph) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(300, 120)
+}
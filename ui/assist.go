@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Whether the evaluation bar and threat overlay are shown. They stay as
+// last chosen for every board opened after, for the rest of the session.
+var (
+	showEvaluation bool
+	showThreats    bool
+)
+
+// boardAssist is the optional evaluation bar and threat overlay shown with
+// a board, while playing or going over a game.
+type boardAssist struct {
+	bar     *evalBar
+	options *fyne.Container // the checkboxes that turn them on and off
+	game    *Game           // the position last shown
+	grid    *fyne.Container
+}
+
+// newBoardAssist makes the bar and checkboxes for a board.
+func newBoardAssist(gw *Game, gridContainer *fyne.Container) *boardAssist {
+	a := &boardAssist{bar: newEvalBar(gw.Players), game: gw, grid: gridContainer}
+	evaluationCheck := widget.NewCheck("Show Evaluation", func(checked bool) {
+		showEvaluation = checked
+		a.update(a.game)
+	})
+	evaluationCheck.SetChecked(showEvaluation)
+	threatsCheck := widget.NewCheck("Show Threats", func(checked bool) {
+		showThreats = checked
+		refreshGrid(a.game, a.grid) // clears the overlay
+		a.update(a.game)
+	})
+	threatsCheck.SetChecked(showThreats)
+	a.options = container.NewHBox(evaluationCheck, threatsCheck)
+	return a
+}
+
+// update shows the bar and overlay for a position. The board should have
+// just been repainted with refreshGrid, which clears the overlay.
+func (a *boardAssist) update(game *Game) {
+	a.game = game
+	if showEvaluation {
+		a.bar.set(game, game.EvalShares())
+		a.bar.container.Show()
+	} else {
+		a.bar.container.Hide()
+	}
+
+	if !showThreats {
+		return
+	}
+	width := len(game.Grid[0])
+	for _, threat := range game.Threats() {
+		cell := a.grid.Objects[threat.Row*width+threat.Column].(*canvas.Circle)
+		cell.StrokeColor = game.Colors[threat.Player]
+		cell.StrokeWidth = 4
+		cell.Refresh()
+	}
+}
+
+// evalBar is a bar split between the sides by how far ahead each is,
+// coloured by their first player.
+type evalBar struct {
+	container *fyne.Container
+	shares    []float64
+}
+
+func newEvalBar(players int) *evalBar {
+	bar := &evalBar{shares: make([]float64, players)}
+	var objects []fyne.CanvasObject
+	for i := 0; i < players; i++ {
+		objects = append(objects, canvas.NewRectangle(color.Transparent))
+	}
+	bar.container = container.New(bar, objects...)
+	bar.container.Hide()
+	return bar
+}
+
+func (bar *evalBar) set(game *Game, shares []SideShare) {
+	for i := range bar.shares {
+		bar.shares[i] = 0
+	}
+	for i, share := range shares {
+		bar.shares[i] = share.Share
+		rect := bar.container.Objects[i].(*canvas.Rectangle)
+		rect.FillColor = game.Colors[share.Players[0]]
+		rect.Refresh()
+	}
+	bar.container.Refresh()
+}
+
+// Layout lines the sides' parts of the bar up from the left; evalBar is
+// its own layout.
+func (bar *evalBar) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	x := float32(0)
+	for i, object := range objects {
+		width := size.Width * float32(bar.shares[i])
+		object.Move(fyne.NewPos(x, 0))
+		object.Resize(fyne.NewSize(width, size.Height))
+		x += width
+	}
+}
+
+func (bar *evalBar) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(200, 20)
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// quickEmotes are the canned messages offered as one-click buttons
+var quickEmotes = []string{"Good game!", "Nice move!", "Oops!", "Well played", "Hurry up!"}
+
+// chatPanel shows what the players of a networked game say and lets the
+// user join in. Muted players' messages are hidden, not thrown away, so
+// unmuting brings them back.
+type chatPanel struct {
+	content  fyne.CanvasObject
+	messages []types.ChatMessage
+	shown    []types.ChatMessage
+	muted    map[int]bool
+	list     *widget.List
+}
+
+// newChatPanel builds the panel. speaker picks which of our seats a message
+// is sent as; spectators can read but not send.
+func newChatPanel(session RemoteSession, speaker func() int, spectating bool, status func(string)) *chatPanel {
+	config := session.Config()
+	p := &chatPanel{muted: make(map[int]bool)}
+	p.list = widget.NewList(
+		func() int { return len(p.shown) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Wrapping = fyne.TextWrapWord
+			return label
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(chatLine(p.shown[id]))
+		},
+	)
+
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(fmt.Sprintf("Say something (up to %d characters)", types.MaxChatLength))
+	allianceCheck := widget.NewCheck("Alliance only", nil)
+	if !config.EnableAlliances {
+		allianceCheck.Hide()
+	}
+	send := func(text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		if utf8.RuneCountInString(text) > types.MaxChatLength {
+			status(fmt.Sprintf("Chat messages can be at most %d characters", types.MaxChatLength))
+			return
+		}
+		chat := types.ChatMessage{Player: speaker(), Text: text, Alliance: allianceCheck.Checked}
+		if err := session.SendChat(chat); err != nil {
+			status("Lost connection to the host!")
+			return
+		}
+		entry.SetText("")
+	}
+	entry.OnSubmitted = send
+	sendButton := widget.NewButton("Send", func() { send(entry.Text) })
+
+	emotes := container.NewHBox()
+	for _, emote := range quickEmotes {
+		emote := emote
+		emotes.Add(widget.NewButton(emote, func() { send(emote) }))
+	}
+
+	var players []string
+	for i := 0; i < config.PlayerCount; i++ {
+		players = append(players, fmt.Sprintf("Player %d", i+1))
+	}
+	muteGroup := widget.NewCheckGroup(players, func(selected []string) {
+		p.muted = make(map[int]bool)
+		for _, name := range selected {
+			var player int
+			fmt.Sscanf(name, "Player %d", &player)
+			p.muted[player-1] = true
+		}
+		p.refresh()
+	})
+	muteGroup.Horizontal = true
+
+	controls := container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(allianceCheck, sendButton), entry),
+		container.NewHScroll(emotes),
+	)
+	if spectating {
+		controls.Hide()
+	}
+	p.content = container.NewBorder(
+		widget.NewLabel("Chat"),
+		container.NewVBox(controls, widget.NewLabel("Mute:"), muteGroup),
+		nil, nil, p.list,
+	)
+	return p
+}
+
+// add shows a new message, unless its sender is muted.
+func (p *chatPanel) add(msg types.ChatMessage) {
+	p.messages = append(p.messages, msg)
+	if !p.muted[msg.Player] {
+		p.shown = append(p.shown, msg)
+		p.list.Refresh()
+		p.list.ScrollToBottom()
+	}
+}
+
+func (p *chatPanel) refresh() {
+	p.shown = p.shown[:0]
+	for _, msg := range p.messages {
+		if !p.muted[msg.Player] {
+			p.shown = append(p.shown, msg)
+		}
+	}
+	p.list.Refresh()
+}
+
+// chatLine formats a message for the chat panel
+func chatLine(msg types.ChatMessage) string {
+	speaker := fmt.Sprintf("Player %d", msg.Player+1)
+	if msg.Name != "" {
+		speaker = fmt.Sprintf("%s (Player %d)", msg.Name, msg.Player+1)
+	}
+	if msg.Alliance {
+		speaker = "[Alliance] " + speaker
+	}
+	return speaker + ": " + msg.Text
+}
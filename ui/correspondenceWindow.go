@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/saves"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// CorrespondenceWindow shows a play-by-file game whose history has already
+// been checked by OpenCorrespondence. The user makes one move for the player
+// whose turn it is; the move is signed with their key and handed to send so
+// the file can be passed on.
+func CorrespondenceWindow(game *saves.Correspondence, gw *Game, key ed25519.PrivateKey, send func(*saves.Correspondence), connectronApp fyne.App) {
+	gameWindow := connectronApp.NewWindow("Connectron - Correspondence")
+	infoLabel := widget.NewLabel("")
+
+	// A finished round's board is shown until the next move starts a new one
+	if gw.RoundOver && !gw.SeriesOver() {
+		infoLabel.SetText(remoteStatusText(gw, false) + " The next move starts a new round.")
+		gw = gw.NextRound()
+	}
+	gridContainer := newGridContainer(gw)
+
+	columnEntry := widget.NewEntry()
+	columnEntry.SetPlaceHolder("Enter Column")
+	dropButton := widget.NewButton("Drop", nil)
+	bombButton := widget.NewButton("Use Bomb Counter", nil)
+	finished := func() {
+		columnEntry.Disable()
+		dropButton.Disable()
+		bombButton.Disable()
+	}
+
+	switch {
+	case gw.SeriesOver():
+		infoLabel.SetText(remoteStatusText(gw, false) + " The game is over.")
+		finished()
+	case !game.CanSign(gw.CurrentTurn, key):
+		infoLabel.SetText(fmt.Sprintf("It's player %d's turn, and their moves are signed by someone else.", gw.CurrentTurn+1))
+		finished()
+	case infoLabel.Text == "":
+		infoLabel.SetText(fmt.Sprintf("Player %d's Turn (you). Make your move, then send the file on.", gw.CurrentTurn+1))
+	}
+	if !gw.BombCounter {
+		bombButton.Disable()
+	}
+
+	play := func(bomb bool) {
+		col, err := strconv.Atoi(columnEntry.Text)
+		if err != nil || col < 1 || col > len(gw.Grid[0]) {
+			infoLabel.SetText("Invalid column number!")
+			return
+		}
+		move := types.Move{Player: gw.CurrentTurn, Column: col - 1, Bomb: bomb}
+		result, err := gw.PlayMove(move)
+		if err != nil {
+			infoLabel.SetText(moveErrorText(err))
+			return
+		}
+		game.Append(move, key)
+		refreshGrid(gw, gridContainer)
+		finished()
+
+		switch {
+		case result.Won:
+			infoLabel.SetText(fmt.Sprintf("Player %d Wins! Send the file on so everyone sees.", result.Player+1))
+		case result.Draw:
+			infoLabel.SetText("The game is a draw! Send the file on so everyone sees.")
+		default:
+			infoLabel.SetText(fmt.Sprintf("Move made. Send the file to player %d.", gw.CurrentTurn+1))
+		}
+		send(game)
+		if gw.SeriesOver() {
+			ShowResultsWindow(gw, connectronApp)
+		}
+	}
+	dropButton.OnTapped = func() { play(false) }
+	bombButton.OnTapped = func() { play(true) }
+
+	content := container.NewBorder(
+		container.NewVBox(infoLabel, columnEntry, dropButton, bombButton),
+		nil, nil, nil, gridContainer,
+	)
+	gameWindow.Resize(fyne.NewSize(800, 600))
+	gameWindow.SetContent(content)
+	gameWindow.Show()
+}
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+
+	"insighthub.uk/connectron/v2/engine"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// EngineSettings say which program plays External Engine seats. The app
+// loads them at start up; engines already running aren't affected by changes.
+var EngineSettings = engine.Settings{MoveTime: engine.DefaultMoveTime}
+
+// engineSeats holds the engines playing a series, which are started the
+// first time they are needed and shared by every round.
+type engineSeats struct {
+	mu      sync.Mutex
+	engines map[int]*engine.Engine
+	rounds  map[int]int   // the round each engine was last told about
+	errs    map[int]error // seats whose engine couldn't start or was stopped
+}
+
+func newEngineSeats() *engineSeats {
+	return &engineSeats{engines: make(map[int]*engine.Engine), rounds: make(map[int]int), errs: make(map[int]error)}
+}
+
+// engineMove asks the current player's engine for its move. It reports
+// false if the engine can't play, in which case the caller should play for it.
+func (g *Game) engineMove() (types.Move, bool) {
+	if g.engines == nil {
+		g.engines = newEngineSeats()
+	}
+	seats := g.engines
+	seats.mu.Lock()
+	defer seats.mu.Unlock()
+
+	seat := g.CurrentTurn
+	if seats.errs[seat] != nil {
+		return types.Move{}, false
+	}
+	e := seats.engines[seat]
+	if e == nil {
+		var err error
+		if e, err = engine.Start(EngineSettings); err != nil {
+			seats.failed(seat, err)
+			return types.Move{}, false
+		}
+		seats.engines[seat] = e
+		seats.rounds[seat] = -1
+	}
+	if seats.rounds[seat] != g.RoundCount {
+		if err := e.NewGame(g.Config(), seat); err != nil {
+			seats.failed(seat, err)
+			return types.Move{}, false
+		}
+		seats.rounds[seat] = g.RoundCount
+	}
+
+	move, err := e.BestMove(g.State(), g.Moves)
+	if err == nil {
+		err = g.checkMove(move)
+	}
+	if err != nil {
+		seats.failed(seat, e.Fail(err))
+		return types.Move{}, false
+	}
+	return move, true
+}
+
+// failed records why a seat's engine can't play. Must be called with mu held.
+func (s *engineSeats) failed(seat int, err error) {
+	s.errs[seat] = err
+	fmt.Printf("Player %d's engine can't play, so the medium AI is taking over: %v\n", seat+1, err)
+}
+
+// checkMove reports whether PlayMove would accept a move, without playing it.
+func (g *Game) checkMove(move types.Move) error {
+	if move.Column < 0 || move.Column >= len(g.Grid[0]) || g.Grid[0][move.Column] != -1 {
+		return fmt.Errorf("%w: column %d", engine.ErrIllegal, move.Column+1)
+	}
+	if move.Bomb && (!g.BombCounter || g.BombCounters[g.CurrentTurn]) {
+		return fmt.Errorf("%w: no bomb counter to use", engine.ErrIllegal)
+	}
+	return nil
+}
+
+// EngineError returns why a seat's external engine isn't playing, or nil if
+// it is (or the seat has no engine).
+func (g *Game) EngineError(seat int) error {
+	if g.engines == nil {
+		return nil
+	}
+	g.engines.mu.Lock()
+	defer g.engines.mu.Unlock()
+	return g.engines.errs[seat]
+}
+
+// CloseEngines stops any external engines playing the series. Call it when
+// the game is finished with.
+func (g *Game) CloseEngines() {
+	if g.engines == nil {
+		return
+	}
+	g.engines.mu.Lock()
+	defer g.engines.mu.Unlock()
+	for seat, e := range g.engines.engines {
+		e.Close()
+		delete(g.engines.engines, seat)
+	}
+}
@@ -0,0 +1,414 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"insighthub.uk/connectron/v2/nn"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Weights score a position for the hard AI. Lines are counted over every
+// run of WinLength cells that only one side has counters in.
+type Weights struct {
+	Open1   float64 `json:"open1"`   // a line one counter short of winning
+	Open2   float64 `json:"open2"`   // two counters short
+	Open3   float64 `json:"open3"`   // three counters short
+	Centre  float64 `json:"centre"`  // a counter in the middle column; less further out
+	Corner  float64 `json:"corner"`  // a corner held, with the corner bonus on
+	Defence float64 `json:"defence"` // how much more the other sides' lines count than our own
+	Bomb    float64 `json:"bomb"`    // a bomb not yet used, with the bomb counter on
+}
+
+// DefaultWeights are used when no tuned profile matches the game.
+var DefaultWeights = Weights{Open1: 50, Open2: 10, Open3: 2, Centre: 3, Corner: 5, Defence: 1.2, Bomb: 30}
+
+// Values lists the weights in a fixed order, for tuning.
+func (w Weights) Values() []float64 {
+	return []float64{w.Open1, w.Open2, w.Open3, w.Centre, w.Corner, w.Defence, w.Bomb}
+}
+
+// WeightsFromValues is the reverse of Values.
+func WeightsFromValues(values []float64) Weights {
+	return Weights{Open1: values[0], Open2: values[1], Open3: values[2], Centre: values[3], Corner: values[4], Defence: values[5], Bomb: values[6]}
+}
+
+// SetWeights makes the hard AI in a seat play with the given weights
+// instead of the best-matching profile.
+func (g *Game) SetWeights(seat int, w Weights) {
+	if g.weights == nil {
+		g.weights = make([]*Weights, g.Players)
+	}
+	g.weights[seat] = &w
+}
+
+// SeatWeights are the weights the hard AI plays with in a seat.
+func (g *Game) SeatWeights(seat int) Weights {
+	if seat < len(g.weights) && g.weights[seat] != nil {
+		return *g.weights[seat]
+	}
+	if profile, ok := BestProfile(loadWeightProfiles(), g.Config()); ok {
+		return profile.Weights
+	}
+	return DefaultWeights
+}
+
+// How far the hard AI looks ahead. It looks less far on big boards, keeping
+// the cells it evaluates per move under searchBudget.
+const (
+	maxSearchDepth = 4
+	searchBudget   = 2000000
+	winScore       = 1e6
+)
+
+// searcher looks ahead from the current position for one player. Moves
+// are tried on copies of the game with every rule applied, so it sees what
+// overflowing columns, solitaire removals and bombs do.
+type searcher struct {
+	g     *Game // where the search starts
+	me    int
+	w     Weights
+	sides []int     // each player's side: the first player in their alliance
+	model *nn.Model // scores positions instead of the weights, if set
+	order []int     // columns, middle first, so the best moves tend to come early
+	bombs bool      // whether bomb drops are tried as well as counters
+	limit int       // the furthest to look ahead, if more than 0
+
+	// From the seat's personality
+	loyalty   float64 // how much allies' lines and counters count
+	bombFirst bool    // try bombs before counters
+}
+
+func newSearcher(g *Game) *searcher {
+	personality := g.PersonalityFor(g.CurrentTurn)
+	s := &searcher{
+		g:         g,
+		me:        g.CurrentTurn,
+		w:         personality.apply(g.SeatWeights(g.CurrentTurn)),
+		sides:     g.sides(),
+		loyalty:   personality.Loyalty,
+		bombFirst: personality.BombFirst,
+	}
+	for col := range g.Grid[0] {
+		s.order = append(s.order, col)
+	}
+	width := len(g.Grid[0])
+	sort.SliceStable(s.order, func(i, j int) bool {
+		return abs(2*s.order[i]-width+1) < abs(2*s.order[j]-width+1)
+	})
+	return s
+}
+
+// depth is how many moves ahead there is time to look.
+func (s *searcher) depth() int {
+	width := len(s.g.Grid[0])
+	cells := width * len(s.g.Grid)
+	branches := width
+	if s.bombs && s.bombsLeft() {
+		branches *= 2
+	}
+	deepest := maxSearchDepth
+	if s.limit > 0 {
+		deepest = min(deepest, s.limit)
+	}
+	depth, nodes := 1, branches
+	for depth < deepest && nodes*branches*cells <= searchBudget {
+		nodes *= branches
+		depth++
+	}
+	return depth
+}
+
+// bombsLeft reports whether anyone still has a bomb to drop.
+func (s *searcher) bombsLeft() bool {
+	if !s.g.BombCounter {
+		return false
+	}
+	for _, used := range s.g.BombCounters {
+		if !used {
+			return true
+		}
+	}
+	return false
+}
+
+// moves lists what the current player could do: a counter in each column,
+// and a bomb in each column if they still have theirs.
+func (s *searcher) moves(g *Game) []types.Move {
+	bomb := s.bombs && g.BombCounter && !g.BombCounters[g.CurrentTurn]
+	moves := make([]types.Move, 0, 2*len(s.order))
+	for _, col := range s.order {
+		counter := types.Move{Player: g.CurrentTurn, Column: col}
+		switch {
+		case !bomb:
+			moves = append(moves, counter)
+		case s.bombFirst:
+			moves = append(moves, types.Move{Player: g.CurrentTurn, Column: col, Bomb: true}, counter)
+		default:
+			moves = append(moves, counter, types.Move{Player: g.CurrentTurn, Column: col, Bomb: true})
+		}
+	}
+	return moves
+}
+
+// bestMove scores every move and picks the best, preferring the middle and
+// keeping the bomb on a tie, unless the personality is keen to drop it. It returns a Column of -1 if the board is full.
+func (s *searcher) bestMove() types.Move {
+	depth := s.depth()
+	best, bestScore := types.Move{Player: s.me, Column: -1}, math.Inf(-1)
+	for _, move := range s.moves(s.g) {
+		score, ok := s.try(s.g, move, depth, bestScore, math.Inf(1))
+		if ok && score > bestScore {
+			best, bestScore = move, score
+		}
+	}
+	return best
+}
+
+// scoreMoves scores every legal move in full, rather than only finding the
+// best, for picking moves that aren't the best on purpose.
+func (s *searcher) scoreMoves() ([]types.Move, []float64) {
+	depth := s.depth()
+	var moves []types.Move
+	var scores []float64
+	for _, move := range s.moves(s.g) {
+		if score, ok := s.try(s.g, move, depth, math.Inf(-1), math.Inf(1)); ok {
+			moves = append(moves, move)
+			scores = append(scores, score)
+		}
+	}
+	return moves, scores
+}
+
+// try plays a move on a copy of the game and scores the position depth
+// moves on.
+func (s *searcher) try(g *Game, move types.Move, depth int, alpha, beta float64) (float64, bool) {
+	next, result, err := g.simulate(move)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case result.Won:
+		// Sooner wins, and later losses, are better
+		score := winScore + float64(depth)
+		if s.sides[result.Player] != s.sides[s.me] {
+			score = -score
+		}
+		return score, true
+	case result.Draw:
+		return 0, true
+	case depth <= 1:
+		return s.evaluate(next), true
+	}
+	return s.search(next, depth-1, alpha, beta), true
+}
+
+// search is minimax with alpha-beta pruning. Every other side is assumed to
+// be playing against us, which with more than two players is pessimistic.
+func (s *searcher) search(g *Game, depth int, alpha, beta float64) float64 {
+	maximising := s.sides[g.CurrentTurn] == s.sides[s.me]
+	best := math.Inf(1)
+	if maximising {
+		best = math.Inf(-1)
+	}
+	moved := false
+	for _, move := range s.moves(g) {
+		score, ok := s.try(g, move, depth, alpha, beta)
+		if !ok {
+			continue
+		}
+		moved = true
+		if maximising {
+			best = math.Max(best, score)
+			alpha = math.Max(alpha, score)
+		} else {
+			best = math.Min(best, score)
+			beta = math.Min(beta, score)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if !moved {
+		return 0 // the board is full: a draw
+	}
+	return best
+}
+
+// evaluate scores the board from our side's point of view.
+func (s *searcher) evaluate(g *Game) float64 {
+	if s.model != nil {
+		position := g.position(s.me, s.sides)
+		return neuralScale * s.model.Predict(nn.Features(position))
+	}
+	height, width := len(g.Grid), len(g.Grid[0])
+	mine := s.sides[s.me]
+	score := 0.0
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			for _, dir := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+				if side, missing, own, ok := s.line(g, row, col, dir); ok {
+					switch {
+					case own:
+						score += s.lineWeight(missing)
+					case side == mine:
+						score += s.loyalty * s.lineWeight(missing)
+					default:
+						score -= s.w.Defence * s.lineWeight(missing)
+					}
+				}
+			}
+
+			player := g.Grid[row][col]
+			if player == -1 {
+				continue
+			}
+			value := s.w.Centre * s.centrality(col)
+			if g.CornerBonus && isCorner(g.Grid, row, col) {
+				value += s.w.Corner
+			}
+			switch {
+			case player == s.me:
+				score += value
+			case s.sides[player] == mine:
+				score += s.loyalty * value
+			default:
+				score -= value
+			}
+		}
+	}
+
+	// A bomb in hand is worth keeping until it can do more than that
+	if g.BombCounter {
+		for player, used := range g.BombCounters {
+			switch {
+			case used:
+			case s.sides[player] == mine:
+				score += s.w.Bomb
+			default:
+				score -= s.w.Bomb
+			}
+		}
+	}
+	return score
+}
+
+// line looks at the WinLength cells from a cell in one direction. It
+// reports whose they are, how many more counters would win and whether
+// they are all ours, if only one side has counters there. Corners count
+// extra with the corner bonus.
+func (s *searcher) line(g *Game, row, col int, dir [2]int) (side, missing int, own, ok bool) {
+	endRow, endCol := row+dir[0]*(g.WinLength-1), col+dir[1]*(g.WinLength-1)
+	if endRow >= len(g.Grid) || endCol < 0 || endCol >= len(g.Grid[0]) {
+		return 0, 0, false, false
+	}
+	side, count := -1, 0
+	own = true
+	for k := 0; k < g.WinLength; k++ {
+		r, c := row+dir[0]*k, col+dir[1]*k
+		player := g.Grid[r][c]
+		if player == -1 {
+			continue
+		}
+		if side != -1 && s.sides[player] != side {
+			return 0, 0, false, false
+		}
+		side = s.sides[player]
+		own = own && player == s.me
+		count++
+		if g.CornerBonus && isCorner(g.Grid, r, c) {
+			count += cornerExtra(g.WinLength)
+		}
+	}
+	if side == -1 {
+		return 0, 0, false, false
+	}
+	return side, max(g.WinLength-count, 1), own, true
+}
+
+func (s *searcher) lineWeight(missing int) float64 {
+	switch missing {
+	case 1:
+		return s.w.Open1
+	case 2:
+		return s.w.Open2
+	case 3:
+		return s.w.Open3
+	}
+	return 0
+}
+
+// centrality is 1 in the middle column, falling to 0 at the edges.
+func (s *searcher) centrality(col int) float64 {
+	middle := float64(len(s.g.Grid[0])-1) / 2
+	if middle == 0 {
+		return 1
+	}
+	return 1 - math.Abs(float64(col)-middle)/middle
+}
+
+// sides maps each player to the first player in their alliance, so allies
+// share a side. Without alliances everyone is on their own side.
+func (g *Game) sides() []int {
+	sides := make([]int, g.Players)
+	for player := range sides {
+		sides[player] = player
+	}
+	if !g.EnableAlliances {
+		return sides
+	}
+	for _, alliance := range g.Alliances {
+		first := -1
+		for _, name := range alliance {
+			var player int
+			if _, err := fmt.Sscanf(name, "Player-%d", &player); err != nil || player < 1 || player > g.Players {
+				continue
+			}
+			if first == -1 {
+				first = player - 1
+			}
+			sides[player-1] = first
+		}
+	}
+	return sides
+}
+
+func isCorner(grid [][]int, row, col int) bool {
+	return (row == 0 || row == len(grid)-1) && (col == 0 || col == len(grid[0])-1)
+}
+
+// cornerExtra is how many extra counters a corner is worth, as in CheckWin.
+func cornerExtra(winLength int) int {
+	if winLength >= 7 {
+		return 2
+	}
+	return 1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// simulate plays a move on a copy of the game, applying every rule just as
+// a real turn would, and leaves this game as it was.
+func (g *Game) simulate(move types.Move) (*Game, TurnResult, error) {
+	return g.simulateAs(g.CurrentTurn, move)
+}
+
+// simulateAs is simulate with the move played by any player, to see what
+// they could do if it were their turn.
+func (g *Game) simulateAs(player int, move types.Move) (*Game, TurnResult, error) {
+	next := *g
+	next.Grid = copyGrid(g.Grid)
+	next.BombCounters = append([]bool(nil), g.BombCounters...)
+	next.Moves, next.History, next.Winners, next.GridHistory, next.Chat = nil, nil, nil, nil, nil
+	next.engines, next.OnSeriesOver, next.OnClosed, next.adaptive = nil, nil, nil, nil
+	next.simulated = true
+	next.CurrentTurn = player
+	result, err := next.PlayMove(move)
+	return &next, result, err
+}
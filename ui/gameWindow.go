@@ -10,10 +10,9 @@ import (
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
-	"encoding/csv"
-	"os"
-	"path/filepath"
 	"sort"
+	"insighthub.uk/connectron/v2/nn"
+	"insighthub.uk/connectron/v2/types"
 )
 
 type Game struct {
@@ -35,6 +34,21 @@ type Game struct {
 	GridHistory    [][][]int
 	BombCounters   []bool
 	Alliances	   [][]string
+	Personalities  []string // each AI seat's play style, see PersonalityFor
+	HintBudget     int // hints each person may take a game, over every round
+	hintPending    bool // the current player has taken a hint for this move
+	Moves          []types.Move
+	History        []types.Move // every move of the series so far, for saving
+	Chat           []types.ChatMessage // said during networked games, across all rounds
+	RoundOver      bool
+	rng            *rand.Rand // the AI's own random numbers, when seeded
+	engines        *engineSeats // external engines, shared by every round
+	weights        []*Weights // the hard AI's weights by seat, when not from a profile
+	model          *nn.Model // the neural AI's network, when not from ModelPath
+	simulated      bool // a copy the AI tries moves out on, which says nothing
+	adaptive       *adaptiveState // how the people are doing against an adaptive AI
+	OnSeriesOver   func(winners []int) // called by the game window once the last round is played; the caller records the result
+	OnClosed       func() // called once the series' last window closes, finished or not
 }
 
 
@@ -131,12 +145,10 @@ func (g *Game) CheckWin(row, column int) bool {
                 if r < 0 || r >= len(g.Grid) || c < 0 || c >= len(g.Grid[0]) {
                     break
                 }
-				fmt.Printf("In same alliance: %d, %d, %t\n", player, g.Grid[r][c], inSameAlliance(player, g.Grid[r][c])) // Debugging statement
                 if g.Grid[r][c] != player && (!g.EnableAlliances || !inSameAlliance(player, g.Grid[r][c])) {
                     break
                 }
                 count++
-                fmt.Printf("Count: %d, Player: %d, Position: (%d, %d)\n", count, player, r, c) // Debugging statement
                 // Apply corner bonus
                 if g.CornerBonus {
                     if (r == 0 || r == len(g.Grid)-1) && (c == 0 || c == len(g.Grid[0])-1) {
@@ -150,7 +162,6 @@ func (g *Game) CheckWin(row, column int) bool {
             }
         }
         if count >= g.WinLength {
-            fmt.Printf("Winning condition met. Count: %d, WinLength: %d\n", count, g.WinLength) // Debugging statement
             return true
         }
     }
@@ -171,45 +182,66 @@ func (g *Game) IsFull() bool {
 // AI Strategies
 func (g *Game) GetAIColumn(aiType int) (int, int) {
 	switch aiType {
-	case 1: // EasyAI
+	case types.EasyAI:
 		return g.easyAI()
-	case 2: // MediumAI
+	case types.MediumAI:
 		return g.mediumAI()
-	case 3: // HardAI
+	case types.HardAI:
 		return g.hardAI()
+	case types.NeuralAI:
+		return g.neuralAI()
+	case types.AdaptiveAI:
+		return g.adaptiveAI()
 	default:
 		return g.easyAI()
 	}
 }
 
+// SetSeed gives the AI its own random numbers, so the same seed always
+// plays the same game.
+func (g *Game) SetSeed(seed int64) {
+	g.rng = rand.New(rand.NewSource(seed))
+}
+
+func (g *Game) randomColumn() int {
+	if g.rng != nil {
+		return g.rng.Intn(len(g.Grid[0]))
+	}
+	return rand.Intn(len(g.Grid[0]))
+}
+
 // EasyAI - Random move
 func (g *Game) easyAI() (int, int) {
 	for {
-		column := rand.Intn(len(g.Grid[0]))
+		column := g.randomColumn()
 		if row, success := g.DropCounter(column); success {
 			return column, row
 		}
 	}
 }
 
-// MediumAI - Block or Win strategy
+// MediumAI - Block or Win strategy. Moves are tried out with every rule
+// applied, so lines made by overflowing or solitaire removals count too.
 func (g *Game) mediumAI() (int, int) {
-	// Try to win or block the player
+	// Win if we can
 	for col := 0; col < len(g.Grid[0]); col++ {
-		// Check if we can win or block in this column
-		if row, success := g.DropCounter(col); success {
-			if g.CheckWin(row, col) {
-				return col, row // If AI wins
-			}
-			g.Grid[row][col] = -1 // Undo move
+		if _, result, err := g.simulate(types.Move{Column: col}); err == nil && result.Won {
+			row, _ := g.DropCounter(col)
+			return col, row
+		}
+	}
 
-			// Block player's winning move
-			g.Grid[row][col] = 2 // Assuming 2 is the opponent's player
-			if g.CheckWin(row, col) {
-				g.Grid[row][col] = -1 // Undo block
+	// Otherwise take the column any other side could win in next
+	sides := g.sides()
+	for col := 0; col < len(g.Grid[0]); col++ {
+		for player := 0; player < g.Players; player++ {
+			if sides[player] == sides[g.CurrentTurn] {
+				continue
+			}
+			if _, result, err := g.simulateAs(player, types.Move{Column: col}); err == nil && result.Won {
+				row, _ := g.DropCounter(col)
 				return col, row
 			}
-			g.Grid[row][col] = -1 // Undo move
 		}
 	}
 
@@ -217,59 +249,16 @@ func (g *Game) mediumAI() (int, int) {
 	return g.easyAI()
 }
 
-// HardAI - Minimax with Alpha-Beta pruning
+// HardAI - Minimax with Alpha-Beta pruning, scoring positions with the
+// seat's weights
 func (g *Game) hardAI() (int, int) {
-	bestMove := g.minimax(4, -10000, 10000, true)
-	return bestMove[0], bestMove[1]
-}
-
-// Minimax algorithm with alpha-beta pruning
-func (g *Game) minimax(depth, alpha, beta int, isMaximizing bool) [2]int {
-	var bestMove [2]int
-
-	if depth == 0 {
-		return bestMove // Base case: return score
-	}
-
-	for col := 0; col < len(g.Grid[0]); col++ {
-		if row, success := g.DropCounter(col); success {
-			// Calculate score of this move
-			score := g.evaluateBoard(isMaximizing)
-
-			// Maximize or minimize the score based on the current player
-			if isMaximizing {
-				if score > alpha {
-					alpha = score
-					bestMove = [2]int{col, row}
-				}
-			} else {
-				if score < beta {
-					beta = score
-					bestMove = [2]int{col, row}
-				}
-			}
-
-			// Undo move
-			g.Grid[row][col] = -1
-		}
-	}
-
-	return bestMove
-}
-
-func (g *Game) evaluateBoard(isMaximizing bool) int {
-	// Evaluate the current board. Positive score for AI, negative for player.
-	if isMaximizing {
-		// AI's perspective
-		return 1
-	}
-	// Player's perspective
-	return -1
+	move := newSearcher(g).bestMove()
+	row, _ := g.DropCounter(move.Column)
+	return move.Column, row
 }
 
 func (g *Game) CheckCornerBonus(row, col int) {
-	fmt.Println(g.CornerBonus)
-	if !g.CornerBonus {
+	if !g.CornerBonus || g.simulated {
 		return // Exit if the corner bonus is not enabled
 	}
     if (row == 0 || row == len(g.Grid)-1) && (col == 0 || col == len(g.Grid[0])-1) {
@@ -284,12 +273,14 @@ func (g *Game) CheckCornerBonus(row, col int) {
     }
 }
 
-func (g *Game) CheckSolitaire() {
+// CheckSolitaire removes counters whose four neighbours all belong to one
+// player, and returns how many were removed.
+func (g *Game) CheckSolitaire() int {
 	if !g.SolitaireRule {
-		fmt.Println("Solitaire rule is not enabled.")
-		return // Exit if the solitaire rule is not enabled
+		return 0 // Exit if the solitaire rule is not enabled
 	}
 
+	removed := 0
 	for row := 0; row < len(g.Grid); row++ {
 		for col := 0; col < len(g.Grid[0]); col++ {
 			player := g.Grid[row][col]
@@ -321,6 +312,7 @@ func (g *Game) CheckSolitaire() {
 					g.Grid[r][col] = g.Grid[r-1][col]
 				}
 				g.Grid[0][col] = -1 // Set the top cell to empty
+				removed++
 
 				// Reset the loop to re-check the updated grid
 				row = 0
@@ -329,6 +321,7 @@ func (g *Game) CheckSolitaire() {
 			}
 		}
 	}
+	return removed
 }
 
 func (g *Game) UseBombCounter(row, col int) {
@@ -343,7 +336,9 @@ func (g *Game) UseBombCounter(row, col int) {
 	}
 }
 
-func (g *Game) CheckOverflow(column int) {
+// CheckOverflow spills counters into the neighbouring columns when a column
+// fills up, and reports whether it did.
+func (g *Game) CheckOverflow(column int) bool {
 	if g.OverflowRule && len(g.Grid) >= 6 {
 		full := true
 		for _, cell := range g.Grid {
@@ -369,9 +364,11 @@ func (g *Game) CheckOverflow(column int) {
 					g.Grid[row][column+1] = g.CurrentTurn
 				}
 			}
+			return true
 		}
 	}
-} 
+	return false
+}
 
 // Main game window
 func MainGameWindow(gw *Game, connectronApp fyne.App) {
@@ -379,97 +376,78 @@ func MainGameWindow(gw *Game, connectronApp fyne.App) {
     infoLabel := widget.NewLabel("Game Start!")
     //gameWindow.SetFullScreen(true)
 
-    gridContainer := container.NewGridWithColumns(len(gw.Grid[0]))	
-    for j := 0; j < len(gw.Grid[0]); j++ {
-        for i := 0; i < len(gw.Grid); i++ {
-            cell := canvas.NewCircle(color.RGBA{240, 240, 240, 255})
-            gridContainer.Add(cell)
-        }
-    }
+    gridContainer := newGridContainer(gw)
+    assist := newBoardAssist(gw, gridContainer)
 
-    var processTurn func(column int, row int) bool
-    processTurn = func(column int, row int) bool {
-        if row == -1 {
-            row, _ = gw.DropCounter(column)
-        }
-        if row == -1 {
-            infoLabel.SetText("Column is full!")
-            return false
+    // External engines carry on into the next round's window
+    nextRound := false
+    gameWindow.SetOnClosed(func() {
+        if !nextRound {
+            gw.CloseEngines()
+            if gw.OnClosed != nil {
+                gw.OnClosed()
+            }
         }
+    })
 
-        cell := gridContainer.Objects[row*len(gw.Grid[0])+column].(*canvas.Circle)
-        cell.FillColor = gw.Colors[gw.CurrentTurn]
-        cell.Refresh()
+    hintLabel := widget.NewLabel("")
+    hintLabel.Wrapping = fyne.TextWrapWord
 
-        // Apply special rules
-        gw.CheckCornerBonus(row, column)
-        gw.CheckSolitaire()
-        gw.CheckOverflow(column)
+    var processTurn func(move types.Move) bool
+    processTurn = func(move types.Move) bool {
+        result, err := gw.PlayMove(move)
+        if err != nil {
+            infoLabel.SetText(moveErrorText(err))
+            return false
+        }
 
         // Update the UI for the newly added counters
-        for i := 0; i < len(gw.Grid); i++ {
-            for j := 0; j < len(gw.Grid[0]); j++ {
-                cell := gridContainer.Objects[i*len(gw.Grid[0])+j].(*canvas.Circle)
-                if gw.Grid[i][j] != -1 {
-                    cell.FillColor = gw.Colors[gw.Grid[i][j]]
-                } else {
-                    cell.FillColor = color.RGBA{240, 240, 240, 255} // Default color for empty cells
-                }
-                cell.Refresh()
+        refreshGrid(gw, gridContainer)
+        assist.update(gw)
+        hintLabel.SetText("")
+
+        if result.Won || result.Draw {
+            if result.Won {
+                infoLabel.SetText(fmt.Sprintf("Player %d Wins!", result.Player+1))
+                fmt.Println("A player won: Round count", gw.RoundCount, "Best of", gw.BestOf)
+            } else {
+                infoLabel.SetText("The game is a draw!")
+                fmt.Println("Draw: Round count", gw.RoundCount, "Best of", gw.BestOf)
             }
-        }
 
-        // Check for win
-        if gw.CheckWin(row, column) {
-            infoLabel.SetText(fmt.Sprintf("Player %d Wins!", gw.CurrentTurn+1))
-            
-            // Record the winner
-            gw.Winners = append(gw.Winners, gw.CurrentTurn+1)
-            gw.GridHistory = append(gw.GridHistory, copyGrid(gw.Grid)) // Store current grid state
-            fmt.Println("A player won: Round count", gw.RoundCount, "Best of", gw.BestOf)
-            if gw.RoundCount+1 < gw.BestOf {
-                gw.RoundCount++
+            if !gw.SeriesOver() {
                 // Start a new game
-                nextGame := NewGame(len(gw.Grid[0]), len(gw.Grid), gw.Players, gw.WinLength, gw.RoundCount, gw.BestOf, gw.PlayerTypes, gw.AIForMissing, gw.CornerBonus, gw.SolitaireRule, gw.BombCounter, gw.OverflowRule, gw.EnableAlliances, gw.Alliances)
-                MainGameWindow(nextGame, connectronApp)
+                nextRound = true
+                MainGameWindow(gw.NextRound(), connectronApp)
                 gameWindow.Close()
             } else {
-                // Show results window
-                ShowResultsWindow(gw, connectronApp)
+                // Show results window, leaving the leaderboard to
+                // whoever asked to hear about the result
+                if gw.OnSeriesOver != nil {
+                    gw.OnSeriesOver(gw.Winners)
+                    showResults(gw, connectronApp)
+                } else {
+                    ShowResultsWindow(gw, connectronApp)
+                }
                 gameWindow.Close()
             }
             return true
         }
 
-        // Check for draw
-        if gw.IsFull() {
-            fmt.Println("Draw: Round count", gw.RoundCount, "Best of", gw.BestOf)
-            infoLabel.SetText("The game is a draw!")
-            gw.Winners = append(gw.Winners, 0) // 0 indicates a draw
-            gw.GridHistory = append(gw.GridHistory, copyGrid(gw.Grid))
-
-            if gw.RoundCount+1 < gw.BestOf {
-                gw.RoundCount++
-                gameWindow.Close()
-                // Start a new game
-                nextGame := NewGame(len(gw.Grid[0]), len(gw.Grid), gw.Players, gw.WinLength, gw.RoundCount, gw.BestOf, gw.PlayerTypes, gw.AIForMissing, gw.CornerBonus, gw.SolitaireRule, gw.BombCounter, gw.OverflowRule, gw.EnableAlliances, gw.Alliances)
-                MainGameWindow(nextGame, connectronApp)
-            } else {
-                gameWindow.Close()
-                // Show results window
-                ShowResultsWindow(gw, connectronApp)
-            }
-            return true
+        turnText := fmt.Sprintf("Player %d's Turn", gw.CurrentTurn+1)
+        if result.Bomb {
+            turnText = fmt.Sprintf("Player %d dropped a bomb! ", result.Player+1) + turnText
         }
-
-        gw.CurrentTurn = (gw.CurrentTurn + 1) % gw.Players
-        infoLabel.SetText(fmt.Sprintf("Player %d's Turn", gw.CurrentTurn+1))
+        infoLabel.SetText(turnText)
 
         // AI move handling
-        if gw.PlayerTypes[gw.CurrentTurn] != -1 {
-            aiColumn, aiRow := gw.GetAIColumn(gw.PlayerTypes[gw.CurrentTurn])
+        if types.IsAI(gw.PlayerTypes[gw.CurrentTurn]) {
+            aiMove := gw.AIMove(gw.PlayerTypes[gw.CurrentTurn])
+            if err := gw.EngineError(gw.CurrentTurn); err != nil {
+                infoLabel.SetText(fmt.Sprintf("Player %d's Turn (medium AI playing, engine stopped: %v)", gw.CurrentTurn+1, err))
+            }
             time.AfterFunc(10*time.Millisecond, func() {
-                processTurn(aiColumn, aiRow)
+                processTurn(aiMove)
             })
         }
         return false
@@ -478,13 +456,13 @@ func MainGameWindow(gw *Game, connectronApp fyne.App) {
     columnEntry := widget.NewEntry()
     columnEntry.SetPlaceHolder("Enter Column")
     dropButton := widget.NewButton("Drop", func() {
-        if gw.PlayerTypes[gw.CurrentTurn] == -1 {
+        if gw.PlayerTypes[gw.CurrentTurn] == types.HumanPlayer {
             col, err := strconv.Atoi(columnEntry.Text)
             if err != nil || col < 1 || col > len(gw.Grid[0]) {
                 infoLabel.SetText("Invalid column number!")
                 return
             }
-            if processTurn(col-1, -1) {
+            if processTurn(types.Move{Column: col - 1}) {
                 columnEntry.SetText("")
             }
         } else {
@@ -493,8 +471,8 @@ func MainGameWindow(gw *Game, connectronApp fyne.App) {
     })
 	
     bombButton := widget.NewButton("Use Bomb Counter", func() {
-        if gw.BombCounters[gw.CurrentTurn] {
-            infoLabel.SetText("You have already used your bomb counter!")
+        if gw.PlayerTypes[gw.CurrentTurn] != types.HumanPlayer {
+            infoLabel.SetText("It's not your turn!")
             return
         }
         col, err := strconv.Atoi(columnEntry.Text)
@@ -502,34 +480,46 @@ func MainGameWindow(gw *Game, connectronApp fyne.App) {
             infoLabel.SetText("Invalid column number!")
             return
         }
-        row, _ := gw.DropCounter(col - 1)
-        if row == -1 {
-            infoLabel.SetText("Column is full!")
-            return
-        }
-        gw.UseBombCounter(row, col-1)
-        gw.BombCounters[gw.CurrentTurn] = true
-        infoLabel.SetText("Bomb counter used!")
-        // Update the UI for the newly added counters
-        for i := 0; i < len(gw.Grid); i++ {
-            for j := 0; j < len(gw.Grid[0]); j++ {
-                cell := gridContainer.Objects[i*len(gw.Grid[0])+j].(*canvas.Circle)
-                if gw.Grid[i][j] != -1 {
-                    cell.FillColor = gw.Colors[gw.Grid[i][j]]
-                } else {
-                    cell.FillColor = color.RGBA{240, 240, 240, 255} // Default color for empty cells
-                }
-                cell.Refresh()
-            }
-        }
+        processTurn(types.Move{Column: col - 1, Bomb: true})
     })
 
 	if !gw.BombCounter {
 		bombButton.Disable()
 	}
 
+    // Hints show the AI's best move for the person whose turn it is, and why
+    hintButton := widget.NewButton("Hint", func() {
+        hint, err := gw.Hint()
+        switch err {
+        case nil:
+        case ErrNoHintFor:
+            infoLabel.SetText("It's not your turn!")
+            return
+        case ErrNoHints:
+            infoLabel.SetText("You have no hints left this game!")
+            return
+        default:
+            infoLabel.SetText(moveErrorText(err))
+            return
+        }
+        action := "Drop a counter"
+        if hint.Move.Bomb {
+            action = "Drop your bomb"
+        }
+        hintLabel.SetText(fmt.Sprintf("Hint: %s in column %d. %s (%d hints left)", action, hint.Move.Column+1, hint.Reason, gw.HintsLeft(gw.CurrentTurn)))
+        columnEntry.SetText(strconv.Itoa(hint.Move.Column + 1))
+        highlightCell(gw, gridContainer, hint.Row, hint.Move.Column)
+    })
+    if gw.HintBudget == 0 {
+        hintButton.Disable()
+    }
+
+    saveButton := widget.NewButton("Save Game", func() {
+        saveGameDialog(gw, gameWindow)
+    })
+
     content := container.NewBorder(
-        container.NewVBox(infoLabel, columnEntry, dropButton, bombButton),
+        container.NewVBox(infoLabel, columnEntry, dropButton, bombButton, hintButton, hintLabel, saveButton, assist.options, assist.bar.container),
         nil, nil, nil, gridContainer,
     )
 
@@ -537,82 +527,107 @@ func MainGameWindow(gw *Game, connectronApp fyne.App) {
     gameWindow.SetContent(content)
     gameWindow.Show()
 
-    if gw.PlayerTypes[gw.CurrentTurn] != -1 {
-        aiColumn, aiRow := gw.GetAIColumn(gw.PlayerTypes[gw.CurrentTurn])
+    if types.IsAI(gw.PlayerTypes[gw.CurrentTurn]) {
+        aiMove := gw.AIMove(gw.PlayerTypes[gw.CurrentTurn])
         time.AfterFunc(100*time.Millisecond, func() {
-            processTurn(aiColumn, aiRow)
+            processTurn(aiMove)
         })
     }
 }
 
-func updateLeaderboard(gw *Game) {
-	filePath := filepath.Join("files", "leaderboard.csv")
-	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		fmt.Println("Error opening leaderboard file:", err)
-		return
-	}
-	defer file.Close()
+// newGridContainer creates the board with an empty circle for every cell
+func newGridContainer(gw *Game) *fyne.Container {
+    gridContainer := container.NewGridWithColumns(len(gw.Grid[0]))
+    for j := 0; j < len(gw.Grid[0]); j++ {
+        for i := 0; i < len(gw.Grid); i++ {
+            cell := canvas.NewCircle(color.RGBA{240, 240, 240, 255})
+            gridContainer.Add(cell)
+        }
+    }
+    return gridContainer
+}
+
+// refreshGrid repaints every cell of the board from the game's grid
+func refreshGrid(gw *Game, gridContainer *fyne.Container) {
+    for i := 0; i < len(gw.Grid); i++ {
+        for j := 0; j < len(gw.Grid[0]); j++ {
+            cell := gridContainer.Objects[i*len(gw.Grid[0])+j].(*canvas.Circle)
+            if gw.Grid[i][j] != -1 {
+                cell.FillColor = gw.Colors[gw.Grid[i][j]]
+            } else {
+                cell.FillColor = color.RGBA{240, 240, 240, 255} // Default color for empty cells
+            }
+            cell.StrokeWidth = 0
+            cell.Refresh()
+        }
+    }
+}
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+// highlightCell rings a cell of the board, such as where a hint would land
+func highlightCell(gw *Game, gridContainer *fyne.Container, row, column int) {
+    if row < 0 {
+        return
+    }
+    cell := gridContainer.Objects[row*len(gw.Grid[0])+column].(*canvas.Circle)
+    cell.StrokeColor = color.RGBA{0, 0, 0, 255}
+    cell.StrokeWidth = 4
+    cell.Refresh()
+}
+
+// moveErrorText turns a rules error from PlayMove into a message for the info label
+func moveErrorText(err error) string {
+    switch err {
+    case ErrInvalidColumn:
+        return "Invalid column number!"
+    case ErrColumnFull:
+        return "Column is full!"
+    case ErrBombUsed:
+        return "You have already used your bomb counter!"
+    case ErrBombDisabled:
+        return "Bomb counters are not enabled!"
+    default:
+        return err.Error()
+    }
+}
+
+func updateLeaderboard(gw *Game) {
+	records, err := ReadLeaderboard()
 	if err != nil {
 		fmt.Println("Error reading leaderboard file:", err)
 		return
 	}
 
-	// Create a map to store player stats
-	playerStats := make(map[string][]string)
-	if len(records) > 1 {
-		for _, record := range records[1:] { // Skip header
-			playerStats[record[0]] = record
-		}
-	}
-
 	// Update player stats based on game results
 	for _, winner := range gw.Winners {
 		if winner == 0 {
 			// Draw case
-			for _, record := range playerStats {
-				drawCount, err := strconv.Atoi(record[5])
-				if err == nil {
-					record[5] = strconv.Itoa(drawCount + 1) // Increment Draw count
-				}
+			for _, record := range records[1:] {
+				addOne(record, 5) // Drawn
 			}
 		} else {
-			player := fmt.Sprintf("Player-%d", winner)
-			if record, exists := playerStats[player]; exists {
-				playedCount, err := strconv.Atoi(record[3])
-				if err == nil {
-					record[3] = strconv.Itoa(playedCount + 1) // Increment Played count
-				}
-				wonCount, err := strconv.Atoi(record[4])
-				if err == nil {
-					record[4] = strconv.Itoa(wonCount + 1) // Increment Won count
-				}
-			} else {
-				playerStats[player] = []string{player, "0", "UUID", "1", "1", "0", "0"}
-			}
+			record := leaderboardRow(&records, fmt.Sprintf("Player-%d", winner))
+			addOne(record, 3) // Played
+			addOne(record, 4) // Won
 		}
 	}
 
-	// Write updated stats back to the file
-	file.Seek(0, 0)
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header
-	writer.Write(records[0])
-
-	// Write updated records
-	for _, record := range playerStats {
-		writer.Write(record)
+	if err := writeLeaderboard(records); err != nil {
+		fmt.Println("Error writing leaderboard file:", err)
 	}
 }
 
+// ShowResultsWindow records a finished series on the leaderboard and shows
+// the results.
 func ShowResultsWindow(gw *Game, connectronApp fyne.App) {
 	updateLeaderboard(gw)
+	if err := recordHints(gw); err != nil {
+		fmt.Println("Error recording hints on the leaderboard:", err)
+	}
+	showResults(gw, connectronApp)
+}
 
+// showResults shows a finished series' results without recording them.
+func showResults(gw *Game, connectronApp fyne.App) {
 	resultsWindow := connectronApp.NewWindow("Series Results")
 	resultsText := "Series Results:\n\n"
 	for i, winner := range gw.Winners {
@@ -649,13 +664,20 @@ func ShowResultsWindow(gw *Game, connectronApp fyne.App) {
 	for i, result := range results {
 		resultsText += fmt.Sprintf("%d. Player %d with %d wins\n", i+1, result.Player, result.Wins)
 	}
+	if summary := gw.AdaptiveSummary(); summary != "" {
+		resultsText += "\n" + summary + "\n"
+	}
 
 	resultsLabel := widget.NewLabel(resultsText)
 	closeButton := widget.NewButton("Close", func() {
 		resultsWindow.Close()
 	})
 
-	resultsWindow.SetContent(container.NewVBox(resultsLabel, closeButton))
+	analyseButton := widget.NewButton("Analyse Game", func() {
+		AnalysisWindow(gw.Record(), connectronApp)
+	})
+
+	resultsWindow.SetContent(container.NewVBox(resultsLabel, analyseButton, closeButton))
 	resultsWindow.Resize(fyne.NewSize(400, 300))
 	resultsWindow.Show()
 }
@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// DefaultHintBudget is how many hints the setup screen offers each person a
+// game to begin with.
+const DefaultHintBudget = 3
+
+// Errors returned by Hint.
+var (
+	ErrNoHints   = errors.New("no hints left this game")
+	ErrNoHintFor = errors.New("hints are only for people playing at this computer")
+)
+
+// Hint is the AI's suggestion for the current player's move.
+type Hint struct {
+	Move   types.Move
+	Row    int    // where the counter would land, for highlighting
+	Reason string // why it's a good move, for showing alongside
+}
+
+// HintsLeft is how many more hints a seat may take this game. The budget
+// covers every round of the series, so it isn't topped up between rounds.
+func (g *Game) HintsLeft(seat int) int {
+	used := 0
+	for _, move := range g.History {
+		if move.Player == seat && move.Hinted {
+			used++
+		}
+	}
+	if g.hintPending && seat == g.CurrentTurn {
+		used++
+	}
+	return max(g.HintBudget-used, 0)
+}
+
+// Hint asks the hard AI for the current player's best move and explains
+// it. It costs one of their hints, though asking again before they move is
+// free, and the move they make is marked as hinted.
+func (g *Game) Hint() (Hint, error) {
+	if g.RoundOver {
+		return Hint{}, ErrRoundOver
+	}
+	if g.PlayerTypes[g.CurrentTurn] != types.HumanPlayer {
+		return Hint{}, ErrNoHintFor
+	}
+	if !g.hintPending && g.HintsLeft(g.CurrentTurn) == 0 {
+		return Hint{}, ErrNoHints
+	}
+
+	s := newSearcher(g)
+	s.bombs = true
+	move := s.bestMove()
+	if move.Column < 0 {
+		return Hint{}, ErrColumnFull
+	}
+	g.hintPending = true
+	return Hint{Move: move, Row: landingRow(g.Grid, move.Column), Reason: g.explain(move)}, nil
+}
+
+// explain says what a move does: win, stop someone else winning, or leave
+// more than one way to win next turn.
+func (g *Game) explain(move types.Move) string {
+	next, result, err := g.simulate(move)
+	if err != nil {
+		return ""
+	}
+	if result.Won {
+		return "It wins the game now."
+	}
+	if result.Draw {
+		return "It fills the board for a draw."
+	}
+
+	sides := g.sides()
+	var reasons []string
+	var blocked []string
+	for player := 0; player < g.Players; player++ {
+		if sides[player] == sides[g.CurrentTurn] {
+			continue
+		}
+		before := g.winningColumns(player)
+		after := next.winningColumns(player)
+		if len(before) > len(after) {
+			blocked = append(blocked, fmt.Sprintf("Player %d", player+1))
+		}
+	}
+	if len(blocked) > 0 {
+		reasons = append(reasons, "It blocks "+strings.Join(blocked, " and ")+" from winning.")
+	}
+
+	switch threats := len(next.winningColumns(g.CurrentTurn)); {
+	case threats >= 2:
+		reasons = append(reasons, "It sets up a double threat: more than one way to win next turn.")
+	case threats == 1:
+		reasons = append(reasons, "It threatens to win next turn.")
+	}
+	if move.Bomb && len(reasons) == 0 {
+		reasons = append(reasons, "The bomb clears away the counters that matter most.")
+	}
+	if len(reasons) == 0 {
+		return "It leaves you in the strongest position the AI can find."
+	}
+	return strings.Join(reasons, " ")
+}
+
+// winningColumns lists the columns a player could win in with a counter
+// if it were their turn now.
+func (g *Game) winningColumns(player int) []int {
+	var columns []int
+	for col := range g.Grid[0] {
+		if _, result, err := g.simulateAs(player, types.Move{Column: col}); err == nil && result.Won {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// landingRow is the row a counter dropped in a column lands in, or -1 if
+// the column is full.
+func landingRow(grid [][]int, column int) int {
+	for row := len(grid) - 1; row >= 0; row-- {
+		if grid[row][column] == -1 {
+			return row
+		}
+	}
+	return -1
+}
+
+// recordHints adds the hints each person took over the series to their
+// leaderboard row.
+func recordHints(gw *Game) error {
+	used := make(map[string]int)
+	for _, move := range gw.History {
+		if move.Hinted {
+			used[fmt.Sprintf("Player-%d", move.Player+1)]++
+		}
+	}
+	records, err := ReadLeaderboard()
+	if err != nil {
+		return err
+	}
+	hints := len(leaderboardHeader) - 1
+	for name, count := range used {
+		record := leaderboardRow(&records, name)
+		total, _ := strconv.Atoi(record[hints])
+		record[hints] = strconv.Itoa(total + count)
+	}
+	return writeLeaderboard(records)
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+func TestHintBudgetCoversSeries(t *testing.T) {
+	g := NewGameFromConfig(types.GameConfig{GridWidth: 7, GridHeight: 6, LineLength: 4, PlayerCount: 2, BestOf: 3,
+		PlayerTypes: []int{types.HumanPlayer, types.HumanPlayer}, HintBudget: 1})
+	hint, err := g.Hint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.PlayMove(hint.Move); err != nil {
+		t.Fatal(err)
+	}
+	g.RoundOver = true // as if someone had won
+
+	next := g.NextRound()
+	if left := next.HintsLeft(0); left != 0 {
+		t.Fatalf("got %d hints left in the next round, want the one used to count", left)
+	}
+	next.CurrentTurn = 0
+	if _, err := next.Hint(); !errors.Is(err, ErrNoHints) {
+		t.Fatalf("got %v, want ErrNoHints", err)
+	}
+	if left := next.HintsLeft(1); left != 1 {
+		t.Fatalf("got %d hints left for the other seat, want 1", left)
+	}
+}
@@ -1,14 +1,107 @@
 package ui
 
 import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/saves"
 	"sort"
 	"strconv"
 )
 
+// LeaderboardPath is the leaderboard file shared by every way of playing.
+var LeaderboardPath = filepath.Join("files", "leaderboard.csv")
+
+// leaderboardHeader starts a new leaderboard, in the columns updateLeaderboard uses
+var leaderboardHeader = []string{"Name", "Score", "UUID", "Played", "Won", "Drawn", "Lost", "Hints"}
+
+// ReadLeaderboard reads the leaderboard with every row, header included,
+// in leaderboardHeader's columns. Leaderboards from before a column was
+// added gain it, with 0 for everyone. A missing file is just the header.
+func ReadLeaderboard() ([][]string, error) {
+	records := [][]string{}
+	file, err := os.Open(LeaderboardPath)
+	if err == nil {
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1 // older rows may be short
+		records, err = reader.ReadAll()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		records = [][]string{{}}
+	}
+	for len(records[0]) < len(leaderboardHeader) {
+		records[0] = append(records[0], leaderboardHeader[len(records[0])])
+	}
+	for i := range records[1:] {
+		for len(records[i+1]) < len(leaderboardHeader) {
+			records[i+1] = append(records[i+1], "0")
+		}
+	}
+	return records, nil
+}
+
+// writeLeaderboard saves the leaderboard, replacing the file.
+func writeLeaderboard(records [][]string) error {
+	if err := os.MkdirAll(filepath.Dir(LeaderboardPath), 0755); err != nil {
+		return err
+	}
+	return saves.WriteCSV(LeaderboardPath, records)
+}
+
+// leaderboardRow finds a player's row, adding one if they aren't on the
+// leaderboard yet.
+func leaderboardRow(records *[][]string, name string) []string {
+	for _, record := range (*records)[1:] {
+		if record[0] == name {
+			return record
+		}
+	}
+	record := []string{name, "0", "UUID", "0", "0", "0", "0", "0"}
+	*records = append(*records, record)
+	return record
+}
+
+// RecordMatch adds a finished series between two named players to the
+// leaderboard. winner is the name of whoever won, or "" for a draw.
+func RecordMatch(player1, player2, winner string) error {
+	records, err := ReadLeaderboard()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{player1, player2} {
+		record := leaderboardRow(&records, name)
+		addOne(record, 3) // Played
+		switch winner {
+		case name:
+			addOne(record, 4) // Won
+		case "":
+			addOne(record, 5) // Drawn
+		default:
+			addOne(record, 6) // Lost
+		}
+	}
+	return writeLeaderboard(records)
+}
+
+func addOne(record []string, col int) {
+	count, _ := strconv.Atoi(record[col])
+	record[col] = strconv.Itoa(count + 1)
+}
+
 // CreateLeaderboard creates a leaderboard UI from the provided 2D array of player data
 func CreateLeaderboard(playerData [][]string) fyne.CanvasObject {
 	// Handle the case where no player data is provided
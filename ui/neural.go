@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"insighthub.uk/connectron/v2/nn"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// ModelPath is where the neural AI's trained network is kept. It is read
+// the first time a neural AI needs it.
+var ModelPath = filepath.Join("files", "model.json")
+
+// neuralScale puts the network's -1 to 1 scores on a similar scale to the
+// hard AI's, well short of a win.
+const neuralScale = 1000
+
+// The network the neural AI plays with, read from ModelPath once
+var (
+	modelOnce sync.Once
+	model     *nn.Model
+)
+
+func loadModel() *nn.Model {
+	modelOnce.Do(func() {
+		var err error
+		model, err = nn.Read(ModelPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Println("Error reading neural AI model:", err)
+		}
+	})
+	return model
+}
+
+// SetModel makes the neural AI play this game with the given network
+// instead of the one at ModelPath.
+func (g *Game) SetModel(m *nn.Model) {
+	g.model = m
+}
+
+// Position is the board from a player's point of view, for the network.
+func (g *Game) Position(me int) nn.Position {
+	return g.position(me, g.sides())
+}
+
+func (g *Game) position(me int, sides []int) nn.Position {
+	return nn.Position{
+		Grid:          g.Grid,
+		Sides:         sides,
+		Me:            me,
+		ToMove:        g.CurrentTurn,
+		WinLength:     g.WinLength,
+		CornerBonus:   g.CornerBonus,
+		SolitaireRule: g.SolitaireRule,
+		BombCounter:   g.BombCounter,
+		OverflowRule:  g.OverflowRule,
+		BombsUsed:     g.BombCounters,
+	}
+}
+
+// neuralModel is the network the neural AI plays this game with, or nil
+// if none has been trained.
+func (g *Game) neuralModel() *nn.Model {
+	if g.model != nil {
+		return g.model
+	}
+	return loadModel()
+}
+
+// NeuralAI - the hard AI's search, scoring positions with the network. It
+// plays as the hard AI until a network has been trained.
+func (g *Game) neuralAI() (int, int) {
+	s := newSearcher(g)
+	s.model = g.neuralModel()
+	move := s.bestMove()
+	row, _ := g.DropCounter(move.Column)
+	return move.Column, row
+}
+
+// searchMove is the hard or neural AI's move, which unlike GetAIColumn may
+// be a bomb. The board is left as it was.
+func (g *Game) searchMove(aiType int) types.Move {
+	s := newSearcher(g)
+	if aiType == types.NeuralAI {
+		s.model = g.neuralModel()
+	}
+	s.bombs = true
+	return s.bestMove()
+}
@@ -0,0 +1,77 @@
+package ui
+
+import "insighthub.uk/connectron/v2/types"
+
+// Personality is a play style for the searching AIs. It changes how they
+// weigh up positions and, between moves that score the same, which they
+// pick.
+type Personality struct {
+	Name  string // as stored in saves, one of types.Personalities
+	Label string // as shown on the setup screen
+
+	// How much more or less than usual each of the seat's weights counts
+	Attack, Defence, Centre, Corner, Bomb float64
+
+	Loyalty   float64 // how much allies' lines and counters count, against our own
+	BombFirst bool    // tries bombs before counters, so a tie goes to dropping one
+}
+
+var personalities = map[string]Personality{
+	"balanced":    {Label: "Balanced"},
+	"aggressive":  {Label: "Aggressive", Attack: 1.5, Defence: 0.6},
+	"defensive":   {Label: "Defensive", Attack: 0.8, Defence: 1.8},
+	"centre":      {Label: "Centre-focused", Centre: 4},
+	"corner":      {Label: "Corner hunter", Corner: 5}, // only different with the corner bonus on
+	"bombs":       {Label: "Bomb-happy", Bomb: 0.1, BombFirst: true},
+	"loyal":       {Label: "Alliance-loyal", Loyalty: 1.5},
+	"opportunist": {Label: "Opportunist", Loyalty: 0.25},
+}
+
+// FindPersonality looks up a personality by name. Unknown names, and "",
+// are balanced.
+func FindPersonality(name string) Personality {
+	p, ok := personalities[name]
+	if !ok {
+		name = types.Personalities[0]
+		p = personalities[name]
+	}
+	p.Name = name
+	// Anything left out is as usual
+	for _, factor := range []*float64{&p.Attack, &p.Defence, &p.Centre, &p.Corner, &p.Bomb, &p.Loyalty} {
+		if *factor == 0 {
+			*factor = 1
+		}
+	}
+	return p
+}
+
+// HasPersonality reports whether an AI of this type plays to a
+// personality. Only the ones that search ahead do: hard, neural and
+// adaptive.
+func HasPersonality(playerType int) bool {
+	switch playerType {
+	case types.HardAI, types.NeuralAI, types.AdaptiveAI:
+		return true
+	}
+	return false
+}
+
+// PersonalityFor is the play style of the AI in a seat.
+func (g *Game) PersonalityFor(seat int) Personality {
+	if seat < len(g.Personalities) {
+		return FindPersonality(g.Personalities[seat])
+	}
+	return FindPersonality("")
+}
+
+// apply scales the weights to the personality.
+func (p Personality) apply(w Weights) Weights {
+	w.Open1 *= p.Attack
+	w.Open2 *= p.Attack
+	w.Open3 *= p.Attack
+	w.Defence *= p.Defence
+	w.Centre *= p.Centre
+	w.Corner *= p.Corner
+	w.Bomb *= p.Bomb
+	return w
+}
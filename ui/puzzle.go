@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"errors"
+
+	"insighthub.uk/connectron/v2/puzzle"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Errors returned while solving a puzzle.
+var (
+	ErrUnsolvable = errors.New("this puzzle can't be won in the moves given")
+	ErrWrongMove  = errors.New("that move doesn't force a win in the moves left")
+	ErrSolved     = errors.New("the puzzle is already solved")
+	ErrTooBig     = errors.New("this puzzle is too big to check")
+)
+
+// PuzzleAttempt is someone working through a puzzle. Their moves are
+// checked against the solver, which accepts any move that still forces a
+// win, and the other seats reply with the most stubborn defence.
+type PuzzleAttempt struct {
+	Puzzle    *puzzle.Puzzle
+	Game      *Game
+	MovesLeft int
+	Mistakes  int // moves tried that didn't keep the win
+	Solved    bool
+	player    int
+}
+
+// StartPuzzle sets a puzzle's position up, checking it can be solved.
+func StartPuzzle(p *puzzle.Puzzle) (*PuzzleAttempt, error) {
+	g := NewGameFromConfig(p.GameConfig())
+	g.Grid = p.Grid()
+	g.CurrentTurn = p.ToMove - 1
+	for _, player := range p.BombsUsed {
+		g.BombCounters[player-1] = true
+	}
+	g.simulated = true // a puzzle, not a real game, so nothing is recorded
+	a := &PuzzleAttempt{Puzzle: p, Game: g, MovesLeft: p.Moves, player: p.ToMove - 1}
+	s := newSolver()
+	wins := s.canForce(g, a.player, p.Moves)
+	if s.spent() {
+		return nil, ErrTooBig
+	}
+	if !wins {
+		return nil, ErrUnsolvable
+	}
+	return a, nil
+}
+
+// Play tries one of the solver's moves. A move that still forces a win is
+// played, followed by every other seat's reply until it is the solver's
+// turn again, and those turns are returned. Any other move is turned down
+// with ErrWrongMove, leaving the board as it was, and counted as a mistake.
+func (a *PuzzleAttempt) Play(move types.Move) ([]TurnResult, error) {
+	if a.Solved {
+		return nil, ErrSolved
+	}
+	next, result, err := a.Game.simulate(move)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Won {
+		s := newSolver()
+		wins := !result.Draw && s.canForce(next, a.player, a.MovesLeft-1)
+		if s.spent() {
+			return nil, ErrTooBig
+		}
+		if !wins {
+			a.Mistakes++
+			return nil, ErrWrongMove
+		}
+	}
+
+	results := []TurnResult{result}
+	a.Game.PlayMove(move)
+	a.MovesLeft--
+	sides := a.Game.sides()
+	for !a.Game.RoundOver && a.Game.CurrentTurn != a.player {
+		reply := a.reply(sides[a.Game.CurrentTurn] == sides[a.player])
+		result, err := a.Game.PlayMove(reply)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	a.Solved = a.Game.RoundOver
+	return results, nil
+}
+
+// Solutions lists every move that still forces a win from here.
+func (a *PuzzleAttempt) Solutions() ([]types.Move, error) {
+	s := newSolver()
+	var moves []types.Move
+	for _, move := range puzzleMoves(a.Game) {
+		next, result, err := a.Game.simulate(move)
+		if err == nil && (result.Won || !result.Draw && s.canForce(next, a.player, a.MovesLeft-1)) {
+			moves = append(moves, move)
+		}
+		if s.spent() {
+			return moves, ErrTooBig
+		}
+	}
+	return moves, nil
+}
+
+// reply picks another seat's move. Allies take the quickest win they can
+// see; everyone else puts the win off for as long as they can.
+func (a *PuzzleAttempt) reply(ally bool) types.Move {
+	g := a.Game
+	s := newSolver()
+	moves := puzzleMoves(g)
+	best := moves[0] // the board isn't full, or the round would be over
+	bestLeft := -1
+	for _, move := range moves {
+		next, result, err := g.simulate(move)
+		if err != nil {
+			continue
+		}
+		if ally && result.Won {
+			return move
+		}
+		// The fewest moves the solver still needs after this one
+		left := 0
+		for left = 1; left <= a.MovesLeft; left++ {
+			if s.canForce(next, a.player, left) {
+				break
+			}
+		}
+		if s.spent() {
+			break // the best found so far will do
+		}
+		if ally && left <= a.MovesLeft && (bestLeft == -1 || left < bestLeft) ||
+			!ally && left > bestLeft {
+			best, bestLeft = move, left
+		}
+	}
+	return best
+}
+
+// solver looks for forced wins, up to a budget of cells looked over, as
+// the hard AI's search is limited by searchBudget.
+type solver struct {
+	budget int
+}
+
+func newSolver() *solver {
+	return &solver{budget: searchBudget}
+}
+
+// spent reports whether the solver ran out of budget, in which case its
+// answers can't be trusted.
+func (s *solver) spent() bool {
+	return s.budget < 0
+}
+
+// canForce reports whether player's side can be sure of winning within n
+// more of player's own moves, whatever the other sides do.
+func (s *solver) canForce(g *Game, player, n int) bool {
+	if n == 0 || g.RoundOver {
+		return false
+	}
+	sides := g.sides()
+	ours := sides[g.CurrentTurn] == sides[player]
+	cells := len(g.Grid) * len(g.Grid[0])
+	for _, move := range puzzleMoves(g) {
+		if s.budget -= cells; s.spent() {
+			return false
+		}
+		next, result, err := g.simulate(move)
+		if err != nil {
+			continue
+		}
+		good := result.Won && ours
+		if !result.Won && !result.Draw {
+			left := n
+			if g.CurrentTurn == player {
+				left--
+			}
+			good = s.canForce(next, player, left)
+		}
+		if good == ours {
+			return ours // found a way through, or a way out
+		}
+	}
+	return !ours
+}
+
+// puzzleMoves lists every move the current player could make.
+func puzzleMoves(g *Game) []types.Move {
+	bomb := g.BombCounter && !g.BombCounters[g.CurrentTurn]
+	var moves []types.Move
+	for col := range g.Grid[0] {
+		if g.Grid[0][col] != -1 {
+			continue // full
+		}
+		moves = append(moves, types.Move{Player: g.CurrentTurn, Column: col})
+		if bomb {
+			moves = append(moves, types.Move{Player: g.CurrentTurn, Column: col, Bomb: true})
+		}
+	}
+	return moves
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// PuzzleWindow lets someone try a puzzle, from an attempt set up with
+// StartPuzzle. onAttempt is called once for each attempt that got anywhere:
+// when the puzzle is solved, or when it is restarted or the window closed
+// after at least one move was tried.
+func PuzzleWindow(attempt *PuzzleAttempt, connectronApp fyne.App, onAttempt func(solved bool, mistakes int)) {
+	p := attempt.Puzzle
+	puzzleWindow := connectronApp.NewWindow("Connectron - Puzzle: " + p.Title)
+	infoLabel := widget.NewLabel("")
+	infoLabel.Wrapping = fyne.TextWrapWord
+	descriptionLabel := widget.NewLabel(p.Description)
+	descriptionLabel.Wrapping = fyne.TextWrapWord
+
+	gw := attempt.Game
+	gridContainer := newGridContainer(gw)
+	tried := false    // a move has been tried this attempt
+	recorded := false // this attempt has been passed to onAttempt
+	finish := func() {
+		if tried && !recorded {
+			recorded = true
+			onAttempt(attempt.Solved, attempt.Mistakes)
+		}
+	}
+
+	status := func() string {
+		if attempt.Solved {
+			return fmt.Sprintf("Solved! Player %d's side wins.", attempt.player+1)
+		}
+		return fmt.Sprintf("Player %d to win in %d %s.", attempt.player+1, attempt.MovesLeft, plural(attempt.MovesLeft, "move", "moves"))
+	}
+	show := func(results []TurnResult) {
+		refreshGrid(gw, gridContainer)
+		if len(results) > 0 {
+			last := results[len(results)-1]
+			highlightCell(gw, gridContainer, last.Row, last.Column)
+		}
+		infoLabel.SetText(status())
+	}
+
+	columnEntry := widget.NewEntry()
+	columnEntry.SetPlaceHolder("Enter Column")
+	play := func(bomb bool) {
+		col, err := strconv.Atoi(columnEntry.Text)
+		if err != nil || col < 1 || col > len(gw.Grid[0]) {
+			infoLabel.SetText("Invalid column number!")
+			return
+		}
+		tried = true
+		results, err := attempt.Play(types.Move{Column: col - 1, Bomb: bomb})
+		switch err {
+		case nil:
+		case ErrWrongMove:
+			infoLabel.SetText(fmt.Sprintf("That move doesn't keep the win. Try again! (%d %s)",
+				attempt.Mistakes, plural(attempt.Mistakes, "mistake", "mistakes")))
+			return
+		case ErrSolved:
+			infoLabel.SetText("You've already solved this puzzle!")
+			return
+		default:
+			infoLabel.SetText(moveErrorText(err))
+			return
+		}
+		columnEntry.SetText("")
+		show(results)
+		if attempt.Solved {
+			finish()
+		}
+	}
+	dropButton := widget.NewButton("Drop", func() { play(false) })
+	bombButton := widget.NewButton("Use Bomb Counter", func() { play(true) })
+	if !gw.BombCounter {
+		bombButton.Disable()
+	}
+
+	restartButton := widget.NewButton("Restart", func() {
+		finish()
+		restarted, err := StartPuzzle(p)
+		if err != nil {
+			infoLabel.SetText(err.Error())
+			return
+		}
+		attempt, gw, tried, recorded = restarted, restarted.Game, false, false
+		show(nil)
+	})
+	puzzleWindow.SetOnClosed(finish)
+
+	content := container.NewBorder(
+		container.NewVBox(descriptionLabel, infoLabel, columnEntry, container.NewHBox(dropButton, bombButton, restartButton)),
+		nil, nil, nil, gridContainer,
+	)
+	show(nil)
+	puzzleWindow.SetContent(content)
+	puzzleWindow.Resize(fyne.NewSize(600, 650))
+	puzzleWindow.Show()
+}
+
+// plural picks the word to go with a count.
+func plural(count int, one, many string) string {
+	if count == 1 {
+		return one
+	}
+	return many
+}
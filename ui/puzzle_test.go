@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"insighthub.uk/connectron/v2/puzzle"
+	"insighthub.uk/connectron/v2/types"
+)
+
+func TestBuiltinPuzzlesSolve(t *testing.T) {
+	puzzles, errs := puzzle.Load(t.TempDir())
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	for _, p := range puzzles {
+		a, err := StartPuzzle(p)
+		if err != nil {
+			t.Errorf("%s: %v", p.ID, err)
+			continue
+		}
+		for !a.Solved {
+			solutions, err := a.Solutions()
+			if err != nil || len(solutions) == 0 {
+				t.Fatalf("%s: no way on with %d moves left: %v", p.ID, a.MovesLeft, err)
+			}
+			if _, err := a.Play(solutions[0]); err != nil {
+				t.Fatalf("%s: %v", p.ID, err)
+			}
+		}
+	}
+}
+
+func TestPuzzleWrongMove(t *testing.T) {
+	p := &puzzle.Puzzle{
+		Version: puzzle.Version, ID: "open-ends", Title: "Open Ends",
+		Config: types.GameConfig{GridWidth: 7, GridHeight: 6, LineLength: 4, PlayerCount: 2, BestOf: 1},
+		Board:  []string{".......", ".......", ".......", ".......", "......2", ".11...2"},
+		ToMove: 1, Moves: 2,
+	}
+	a, err := StartPuzzle(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Play(types.Move{Column: 0}); !errors.Is(err, ErrWrongMove) || a.Mistakes != 1 {
+		t.Fatalf("got %v with %d mistakes, want ErrWrongMove and 1", err, a.Mistakes)
+	}
+	if _, err := a.Play(types.Move{Column: 3}); err != nil {
+		t.Fatal(err)
+	}
+	// Whichever end was left open wins
+	solutions, _ := a.Solutions()
+	if len(solutions) != 1 {
+		t.Fatalf("got solutions %v, want the open end", solutions)
+	}
+	if _, err := a.Play(solutions[0]); err != nil || !a.Solved {
+		t.Fatalf("got %v, solved %v", err, a.Solved)
+	}
+}
+
+func TestPuzzleSolverBudget(t *testing.T) {
+	// The biggest puzzle allowed, with nothing on the board yet
+	board := make([]string, puzzle.MaxSize)
+	for i := range board {
+		board[i] = strings.Repeat(".", puzzle.MaxSize)
+	}
+	p := &puzzle.Puzzle{
+		Version: puzzle.Version, ID: "huge", Title: "Huge",
+		Config: types.GameConfig{GridWidth: puzzle.MaxSize, GridHeight: puzzle.MaxSize, LineLength: 4,
+			PlayerCount: puzzle.MaxPlayers, BestOf: 1, BombCounter: true},
+		Board: board, ToMove: 1, Moves: puzzle.MaxMoves,
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := StartPuzzle(p); !errors.Is(err, ErrTooBig) && !errors.Is(err, ErrUnsolvable) {
+		t.Fatalf("got %v, want the solver to give up", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("the solver took %v", elapsed)
+	}
+}
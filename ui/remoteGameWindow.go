@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// RemoteSession is a connection to a game hosted elsewhere. The host applies
+// the rules, so the window only sends moves and draws the states it gets back.
+type RemoteSession interface {
+	Config() types.GameConfig
+	Seats() []int
+	SendMove(move types.Move) error
+	Updates() <-chan types.GameState
+	Notices() <-chan string
+	SendChat(chat types.ChatMessage) error
+	Chat() <-chan types.ChatMessage
+	Close() error
+}
+
+// hosting reports whether a session is the host's own, on the machine
+// running the game's server. Only the host records networked games on its
+// leaderboard, so each series is recorded once.
+func hosting(session RemoteSession) bool {
+	host, ok := session.(interface{ Hosting() bool })
+	return ok && host.Hosting()
+}
+
+// RemoteGameWindow shows a networked game, letting the user play the seats
+// the session owns.
+func RemoteGameWindow(session RemoteSession, connectronApp fyne.App) {
+	gw := NewGameFromConfig(session.Config())
+	spectating := len(session.Seats()) == 0
+	title := "Connectron - Network Game"
+	if spectating {
+		title = "Connectron - Spectating"
+	}
+	gameWindow := connectronApp.NewWindow(title)
+	infoLabel := widget.NewLabel("Waiting for the host...")
+	spectatorLabel := widget.NewLabel("")
+	gridContainer := newGridContainer(gw)
+
+	ownsTurn := func() bool {
+		for _, seat := range session.Seats() {
+			if seat == gw.CurrentTurn {
+				return true
+			}
+		}
+		return false
+	}
+
+	columnEntry := widget.NewEntry()
+	columnEntry.SetPlaceHolder("Enter Column")
+	sendMove := func(bomb bool) {
+		if gw.RoundOver || !ownsTurn() {
+			infoLabel.SetText("It's not your turn!")
+			return
+		}
+		col, err := strconv.Atoi(columnEntry.Text)
+		if err != nil || col < 1 || col > len(gw.Grid[0]) {
+			infoLabel.SetText("Invalid column number!")
+			return
+		}
+		if err := session.SendMove(types.Move{Player: gw.CurrentTurn, Column: col - 1, Bomb: bomb}); err != nil {
+			infoLabel.SetText("Lost connection to the host!")
+		}
+	}
+	dropButton := widget.NewButton("Drop", func() { sendMove(false) })
+	bombButton := widget.NewButton("Use Bomb Counter", func() { sendMove(true) })
+	if !gw.BombCounter || spectating {
+		bombButton.Disable()
+	}
+	if spectating {
+		columnEntry.Disable()
+		dropButton.Disable()
+	}
+
+	// Chat as whichever of our players is taking their turn, or the first
+	speaker := func() int {
+		if ownsTurn() {
+			return gw.CurrentTurn
+		}
+		if seats := session.Seats(); len(seats) > 0 {
+			return seats[0]
+		}
+		return -1
+	}
+	chat := newChatPanel(session, speaker, spectating, infoLabel.SetText)
+
+	content := container.NewBorder(
+		container.NewVBox(infoLabel, spectatorLabel, columnEntry, dropButton, bombButton),
+		nil, nil, container.NewGridWrap(fyne.NewSize(300, 560), chat.content), gridContainer,
+	)
+
+	gameWindow.Resize(fyne.NewSize(1100, 600))
+	gameWindow.SetContent(content)
+	gameWindow.SetOnClosed(func() { session.Close() })
+	gameWindow.Show()
+
+	resultsShown := false
+	go func() {
+		for {
+			select {
+			case state, ok := <-session.Updates():
+				if !ok {
+					infoLabel.SetText("Disconnected from the host!")
+					return
+				}
+				gw.ApplyState(state)
+				refreshGrid(gw, gridContainer)
+				infoLabel.SetText(remoteStatusText(gw, ownsTurn()))
+				spectatorLabel.SetText(spectatorText(state.Spectators))
+				if state.SeriesOver && !resultsShown && (!spectating || hosting(session)) {
+					resultsShown = true
+					if hosting(session) {
+						ShowResultsWindow(gw, connectronApp)
+					} else {
+						showResults(gw, connectronApp) // the host records the series
+					}
+				}
+			case notice := <-session.Notices():
+				infoLabel.SetText(notice)
+			case msg := <-session.Chat():
+				chat.add(msg)
+			}
+		}
+	}()
+}
+
+// spectatorText shows how many people are watching
+func spectatorText(spectators int) string {
+	switch spectators {
+	case 0:
+		return ""
+	case 1:
+		return "1 spectator watching"
+	default:
+		return fmt.Sprintf("%d spectators watching", spectators)
+	}
+}
+
+// remoteStatusText describes whose turn it is, or how the round ended
+func remoteStatusText(gw *Game, yourTurn bool) string {
+	if gw.RoundOver {
+		winner := gw.Winners[len(gw.Winners)-1]
+		if winner == 0 {
+			return "The game is a draw!"
+		}
+		return fmt.Sprintf("Player %d Wins!", winner)
+	}
+	if yourTurn {
+		return fmt.Sprintf("Player %d's Turn (you)", gw.CurrentTurn+1)
+	}
+	return fmt.Sprintf("Player %d's Turn", gw.CurrentTurn+1)
+}
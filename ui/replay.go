@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+
+	"insighthub.uk/connectron/v2/saves"
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Replay plays a recorded game from the start of the series, checking every
+// move against the rules. Rounds are moved on as they finish, so the result
+// is the game as it stands after the last move. Going back over moves
+// records nothing, so the game comes back simulated.
+func Replay(config types.GameConfig, moves []types.Move) (*Game, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	g := NewGameFromConfig(config)
+	g.simulated = true
+	for i, move := range moves {
+		if g.RoundOver {
+			if g.SeriesOver() {
+				return nil, fmt.Errorf("move %d: the game is already over", i+1)
+			}
+			g = g.NextRound()
+			g.simulated = true
+		}
+		if move.Player != g.CurrentTurn {
+			return nil, fmt.Errorf("move %d: it was player %d's turn, not player %d's", i+1, g.CurrentTurn+1, move.Player+1)
+		}
+		if _, err := g.PlayMove(move); err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+	return g, nil
+}
+
+// OpenCorrespondence checks a move file's signatures and replays its history
+// through the rules, returning the game as it now stands.
+func OpenCorrespondence(game *saves.Correspondence) (*Game, error) {
+	if err := game.Verify(); err != nil {
+		return nil, err
+	}
+	return Replay(game.Config, game.PlainMoves())
+}
+
+// Record is the game so far in the form it is saved in.
+func (g *Game) Record() saves.GameRecord {
+	config := g.Config()
+	config.PlayerTypes = append([]int(nil), config.PlayerTypes...)
+	return saves.GameRecord{
+		Config: config,
+		Moves:  append([]types.Move(nil), g.History...),
+		Chat:   append([]types.ChatMessage(nil), g.Chat...),
+	}
+}
+
+// LoadGame rebuilds a saved game, checking its moves against the rules.
+// Only the moves played from here on are recorded.
+func LoadGame(record saves.GameRecord) (*Game, error) {
+	g, err := Replay(record.Config, record.Moves)
+	if err != nil {
+		return nil, err
+	}
+	g.simulated = false // carried on live
+	g.Chat = record.Chat
+	return g, nil
+}
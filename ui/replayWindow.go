@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"insighthub.uk/connectron/v2/saves"
+)
+
+// replayInterval is how long each move is shown when playing a replay
+const replayInterval = 700 * time.Millisecond
+
+// ReplayWindow steps through a saved game one move at a time. The record
+// should already have been checked with LoadGame.
+func ReplayWindow(record saves.GameRecord, connectronApp fyne.App) {
+	replayWindow := connectronApp.NewWindow("Connectron - Replay")
+	infoLabel := widget.NewLabel("")
+	chatLabel := widget.NewLabel("")
+	chatLabel.Wrapping = fyne.TextWrapWord
+
+	gw := NewGameFromConfig(record.Config)
+	gridContainer := newGridContainer(gw)
+	assist := newBoardAssist(gw, gridContainer)
+	position := 0 // moves shown
+
+	show := func(moves int) {
+		game, err := Replay(record.Config, record.Moves[:moves])
+		if err != nil {
+			infoLabel.SetText("This replay doesn't follow the rules: " + err.Error())
+			return
+		}
+		position = moves
+		gw.ApplyState(game.State())
+		refreshGrid(gw, gridContainer)
+		assist.update(game)
+
+		status := fmt.Sprintf("Round %d of %d, move %d of %d. ", game.RoundCount+1, game.BestOf, moves, len(record.Moves))
+		if game.RoundOver {
+			status += remoteStatusText(game, false)
+		} else {
+			status += fmt.Sprintf("Player %d's Turn", game.CurrentTurn+1)
+		}
+		if moves > 0 && record.Moves[moves-1].Hinted {
+			status += fmt.Sprintf(" (Player %d took a hint for the last move)", record.Moves[moves-1].Player+1)
+		}
+		infoLabel.SetText(status)
+		chatLabel.SetText(replayChat(record, game))
+	}
+
+	slider := widget.NewSlider(0, float64(len(record.Moves)))
+	slider.Step = 1
+	slider.OnChanged = func(value float64) {
+		if int(value) != position {
+			show(int(value))
+		}
+	}
+	step := func(by int) {
+		moves := position + by
+		if moves < 0 || moves > len(record.Moves) {
+			return
+		}
+		slider.SetValue(float64(moves))
+	}
+
+	var playing chan struct{} // closed to stop playing
+	stop := func() {
+		if playing != nil {
+			close(playing)
+			playing = nil
+		}
+	}
+	playButton := widget.NewButton("Play", nil)
+	playButton.OnTapped = func() {
+		stop()
+		if playButton.Text == "Pause" {
+			playButton.SetText("Play")
+			return
+		}
+		if position >= len(record.Moves) {
+			slider.SetValue(0) // play from the start again
+		}
+		playButton.SetText("Pause")
+		done := make(chan struct{})
+		playing = done
+		go func() {
+			ticker := time.NewTicker(replayInterval)
+			defer ticker.Stop()
+			for position < len(record.Moves) {
+				select {
+				case <-ticker.C:
+					step(1)
+				case <-done:
+					return
+				}
+			}
+			playButton.SetText("Play")
+		}()
+	}
+
+	controls := container.NewHBox(
+		widget.NewButton("|<", func() { slider.SetValue(0) }),
+		widget.NewButton("<", func() { step(-1) }),
+		playButton,
+		widget.NewButton(">", func() { step(1) }),
+		widget.NewButton(">|", func() { slider.SetValue(float64(len(record.Moves))) }),
+		widget.NewButton("Analyse", func() { AnalysisWindow(record, connectronApp) }),
+	)
+	content := container.NewBorder(
+		container.NewVBox(infoLabel, slider, controls, assist.options, assist.bar.container),
+		chatLabel, nil, nil, gridContainer,
+	)
+
+	show(0)
+	replayWindow.SetOnClosed(stop)
+	replayWindow.Resize(fyne.NewSize(800, 650))
+	replayWindow.SetContent(content)
+	replayWindow.Show()
+}
+
+// replayChat is the last few things said up to the move being shown
+func replayChat(record saves.GameRecord, game *Game) string {
+	const lines = 3
+	var said []string
+	for _, msg := range record.Chat {
+		if msg.Round < game.RoundCount || msg.Round == game.RoundCount && msg.Move <= len(game.Moves) {
+			said = append(said, chatLine(msg))
+		}
+	}
+	if len(said) > lines {
+		said = said[len(said)-lines:]
+	}
+	text := ""
+	for _, line := range said {
+		text += line + "\n"
+	}
+	return text
+}
+
+// saveGameDialog asks where to save the game so far, so it can be carried
+// on or replayed later
+func saveGameDialog(gw *Game, parent fyne.Window) {
+	dialog.ShowFileSave(func(file fyne.URIWriteCloser, err error) {
+		if err != nil || file == nil {
+			return
+		}
+		file.Close()
+		if err := saves.WriteGame(file.URI().Path(), gw.Record()); err != nil {
+			dialog.ShowError(err, parent)
+		}
+	}, parent)
+}
@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"errors"
+	"math/rand"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Errors returned by PlayMove when a move breaks the rules.
+var (
+	ErrRoundOver     = errors.New("the round is already over")
+	ErrInvalidColumn = errors.New("invalid column number")
+	ErrColumnFull    = errors.New("column is full")
+	ErrBombDisabled  = errors.New("bomb counters are not enabled")
+	ErrBombUsed      = errors.New("bomb counter already used")
+)
+
+// TurnResult describes what happened when a move was played.
+type TurnResult struct {
+	Player int
+	Row    int
+	Column int
+	Won    bool
+	Draw   bool
+
+	// Special rules set off by the move
+	Bomb      bool
+	Solitaire int // counters removed
+	Overflow  bool
+}
+
+// NewGameFromConfig builds a fresh game (round 0) from the setup options.
+func NewGameFromConfig(cfg types.GameConfig) *Game {
+	g := NewGame(cfg.GridWidth, cfg.GridHeight, cfg.PlayerCount, cfg.LineLength, 0, cfg.BestOf, cfg.PlayerTypes, cfg.AIForMissing, cfg.CornerBonus, cfg.SolitaireRule, cfg.BombCounter, cfg.OverflowRule, cfg.EnableAlliances, cfg.Alliances)
+	g.Personalities = cfg.Personalities
+	g.HintBudget = cfg.HintBudget
+	return g
+}
+
+// Config returns the setup options this game was created with.
+func (g *Game) Config() types.GameConfig {
+	return types.GameConfig{
+		GridWidth:       len(g.Grid[0]),
+		GridHeight:      len(g.Grid),
+		LineLength:      g.WinLength,
+		PlayerCount:     g.Players,
+		BestOf:          g.BestOf,
+		PlayerTypes:     g.PlayerTypes,
+		AIForMissing:    g.AIForMissing,
+		CornerBonus:     g.CornerBonus,
+		SolitaireRule:   g.SolitaireRule,
+		BombCounter:     g.BombCounter,
+		OverflowRule:    g.OverflowRule,
+		EnableAlliances: g.EnableAlliances,
+		Alliances:       g.Alliances,
+		Personalities:   g.Personalities,
+		HintBudget:      g.HintBudget,
+	}
+}
+
+// PlayMove plays a move for the current player, applying every special rule,
+// and moves the turn on. The board is left untouched if the move is illegal.
+func (g *Game) PlayMove(move types.Move) (TurnResult, error) {
+	if g.RoundOver {
+		return TurnResult{}, ErrRoundOver
+	}
+	if move.Column < 0 || move.Column >= len(g.Grid[0]) {
+		return TurnResult{}, ErrInvalidColumn
+	}
+	if move.Bomb {
+		if !g.BombCounter {
+			return TurnResult{}, ErrBombDisabled
+		}
+		if g.BombCounters[g.CurrentTurn] {
+			return TurnResult{}, ErrBombUsed
+		}
+	}
+
+	if g.Grid[0][move.Column] != -1 {
+		return TurnResult{}, ErrColumnFull
+	}
+	g.judgeMove(move)
+
+	before := copyGrid(g.Grid)
+	row, _ := g.DropCounter(move.Column)
+	move.Player = g.CurrentTurn
+	move.Hinted = move.Hinted || g.hintPending
+	g.hintPending = false
+	g.Moves = append(g.Moves, move)
+	g.History = append(g.History, move)
+	result := TurnResult{Player: g.CurrentTurn, Row: row, Column: move.Column}
+
+	if move.Bomb {
+		g.UseBombCounter(row, move.Column)
+		g.BombCounters[g.CurrentTurn] = true
+		result.Bomb = true
+	} else {
+		g.CheckCornerBonus(row, move.Column)
+		result.Solitaire = g.CheckSolitaire()
+		result.Overflow = g.CheckOverflow(move.Column)
+
+		// Overflowing and solitaire removals move counters too, so any of
+		// the mover's counters that changed place can finish a line
+		if g.wonSince(before) {
+			result.Won = true
+			g.endRound(g.CurrentTurn + 1)
+			return result, nil
+		}
+	}
+
+	if g.IsFull() {
+		result.Draw = true
+		g.endRound(0) // 0 indicates a draw
+		return result, nil
+	}
+
+	g.CurrentTurn = (g.CurrentTurn + 1) % g.Players
+	return result, nil
+}
+
+// wonSince reports whether the current player has a line through any cell
+// that became theirs since the board looked like before.
+func (g *Game) wonSince(before [][]int) bool {
+	for r, row := range g.Grid {
+		for c, cell := range row {
+			if cell == g.CurrentTurn && before[r][c] != cell && g.CheckWin(r, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// endRound records the winner of the round (0 for a draw) and the final board.
+func (g *Game) endRound(winner int) {
+	g.Winners = append(g.Winners, winner)
+	g.GridHistory = append(g.GridHistory, copyGrid(g.Grid))
+	g.RoundOver = true
+	g.adaptRound(winner)
+}
+
+// SeriesOver reports whether the last round of the best-of series has been played.
+func (g *Game) SeriesOver() bool {
+	return g.RoundOver && g.RoundCount+1 >= g.BestOf
+}
+
+// NextRound starts the next round of the series with the same settings,
+// carrying over the results so far.
+func (g *Game) NextRound() *Game {
+	next := NewGameFromConfig(g.Config())
+	next.RoundCount = g.RoundCount + 1
+	next.Winners = g.Winners
+	next.GridHistory = g.GridHistory
+	next.History = g.History
+	next.Chat = g.Chat
+	next.rng = g.rng
+	next.engines = g.engines
+	next.weights = g.weights
+	next.model = g.model
+	next.adaptive = g.adaptive
+	next.OnSeriesOver = g.OnSeriesOver
+	next.OnClosed = g.OnClosed
+	return next
+}
+
+// Copy is a copy of the game to work out an AI move on away from this one,
+// such as without holding the lock that guards it. The copy shares this
+// game's engines and adaptive AI state, which guard themselves, and has
+// random numbers of its own.
+func (g *Game) Copy() *Game {
+	// Set these up first, so the copy doesn't start its own
+	if g.engines == nil {
+		g.engines = newEngineSeats()
+	}
+	g.adaptiveState()
+
+	c := *g
+	c.Grid = copyGrid(g.Grid)
+	c.BombCounters = append([]bool(nil), g.BombCounters...)
+	c.Moves = append([]types.Move(nil), g.Moves...)
+	c.History = append([]types.Move(nil), g.History...)
+	c.Winners = append([]int(nil), g.Winners...)
+	c.GridHistory = append([][][]int(nil), g.GridHistory...)
+	c.Chat = append([]types.ChatMessage(nil), g.Chat...)
+	c.OnSeriesOver, c.OnClosed = nil, nil
+	if g.rng != nil {
+		c.rng = rand.New(rand.NewSource(g.rng.Int63()))
+	}
+	return &c
+}
+
+// AIMove asks the AI of the given level for its move without changing the board.
+func (g *Game) AIMove(aiType int) types.Move {
+	if aiType == types.ExternalEngine {
+		if move, ok := g.engineMove(); ok {
+			return move
+		}
+		aiType = types.MediumAI // stands in for an engine that can't play
+	}
+
+	if aiType == types.AdaptiveAI {
+		if move := g.adaptiveMove(); move.Column >= 0 {
+			return move
+		}
+	}
+
+	// The searching AIs weigh up bombs too
+	if aiType == types.HardAI || aiType == types.NeuralAI {
+		if move := g.searchMove(aiType); move.Column >= 0 {
+			return move
+		}
+	}
+
+	saved := copyGrid(g.Grid)
+	column, _ := g.GetAIColumn(aiType)
+	g.Grid = saved
+
+	// The AI can come back with a full column when it finds nothing better
+	if column < 0 || column >= len(g.Grid[0]) || g.Grid[0][column] != -1 {
+		for col := range g.Grid[0] {
+			if g.Grid[0][col] == -1 {
+				column = col
+				break
+			}
+		}
+	}
+	return types.Move{Player: g.CurrentTurn, Column: column}
+}
+
+// State takes a snapshot of the game for sending to other players.
+func (g *Game) State() types.GameState {
+	state := types.GameState{
+		Grid:         copyGrid(g.Grid),
+		CurrentTurn:  g.CurrentTurn,
+		RoundCount:   g.RoundCount,
+		Winners:      append([]int(nil), g.Winners...),
+		BombCounters: append([]bool(nil), g.BombCounters...),
+		RoundOver:    g.RoundOver,
+		SeriesOver:   g.SeriesOver(),
+	}
+	if len(g.Moves) > 0 {
+		last := g.Moves[len(g.Moves)-1]
+		state.LastMove = &last
+	}
+	return state
+}
+
+// ApplyState overwrites the game with a snapshot taken by State.
+func (g *Game) ApplyState(state types.GameState) {
+	g.Grid = copyGrid(state.Grid)
+	g.CurrentTurn = state.CurrentTurn
+	g.RoundCount = state.RoundCount
+	g.Winners = append([]int(nil), state.Winners...)
+	g.BombCounters = append([]bool(nil), state.BombCounters...)
+	g.RoundOver = state.RoundOver
+}
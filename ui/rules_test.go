@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"testing"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+func TestOverflowFinishesLine(t *testing.T) {
+	g := NewGameFromConfig(types.GameConfig{GridWidth: 7, GridHeight: 6, LineLength: 4, PlayerCount: 2, BestOf: 1,
+		PlayerTypes: []int{types.HumanPlayer, types.HumanPlayer}, OverflowRule: true})
+	// Player 1 has the bottom row but for column 2, and column 3 has one
+	// space left. Filling it spills a counter into column 2.
+	for r, row := range []string{
+		".......",
+		"...2...",
+		"...1...",
+		"...2...",
+		"...1...",
+		"11.1.22",
+	} {
+		for c, cell := range row {
+			if cell != '.' {
+				g.Grid[r][c] = int(cell - '1')
+			}
+		}
+	}
+
+	result, err := g.PlayMove(types.Move{Column: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Overflow || !result.Won {
+		t.Fatalf("got overflow %v, won %v; want the overflow counter to win", result.Overflow, result.Won)
+	}
+	if len(g.Winners) != 1 || g.Winners[0] != 1 {
+		t.Fatalf("got winners %v, want player 1", g.Winners)
+	}
+}
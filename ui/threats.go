@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"math"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// Threat is an empty cell a player would complete a line with if they
+// dropped a counter there on their next turn.
+type Threat struct {
+	Row, Column int
+	Player      int
+}
+
+// Threats lists every cell any player could win with next turn. Each move
+// is tried with every rule applied, so the corner bonus, overflowing
+// columns and any line length count just as they would in play.
+func (g *Game) Threats() []Threat {
+	var threats []Threat
+	if g.RoundOver {
+		return threats
+	}
+	for player := 0; player < g.Players; player++ {
+		for col := range g.Grid[0] {
+			if _, result, err := g.simulateAs(player, types.Move{Column: col}); err == nil && result.Won {
+				threats = append(threats, Threat{Row: result.Row, Column: col, Player: player})
+			}
+		}
+	}
+	return threats
+}
+
+// SideShare is how much of the evaluation bar a side gets: allies share
+// one, and the shares add up to 1.
+type SideShare struct {
+	Players []int
+	Share   float64
+}
+
+// EvalShares weighs up who is ahead, by the hard AI's evaluation of the
+// board from each side's point of view. A side that has won, or can win on
+// this turn, gets the whole bar.
+func (g *Game) EvalShares() []SideShare {
+	sides := g.sides()
+	var shares []SideShare
+	index := make(map[int]int) // side to its place in shares
+	for player, side := range sides {
+		if i, ok := index[side]; ok {
+			shares[i].Players = append(shares[i].Players, player)
+			continue
+		}
+		index[side] = len(shares)
+		shares = append(shares, SideShare{Players: []int{player}})
+	}
+
+	winner := -1
+	switch {
+	case g.RoundOver && len(g.Winners) > 0 && g.Winners[len(g.Winners)-1] > 0:
+		winner = g.Winners[len(g.Winners)-1] - 1
+	case !g.RoundOver && len(g.winningColumns(g.CurrentTurn)) > 0:
+		winner = g.CurrentTurn
+	}
+	if winner >= 0 {
+		shares[index[sides[winner]]].Share = 1
+		return shares
+	}
+
+	s := newSearcher(g)
+	scores := make([]float64, len(shares))
+	highest := math.Inf(-1)
+	for i, share := range shares {
+		s.me = share.Players[0]
+		s.w, s.loyalty = g.SeatWeights(s.me), 1
+		scores[i] = s.evaluate(g) / evalScale
+		highest = math.Max(highest, scores[i])
+	}
+	total := 0.0
+	for i := range shares {
+		shares[i].Share = math.Exp(scores[i] - highest)
+		total += shares[i].Share
+	}
+	for i := range shares {
+		shares[i].Share /= total
+	}
+	return shares
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"insighthub.uk/connectron/v2/types"
+)
+
+// WeightsVersion is written into the weights file. Bump it whenever the
+// format changes in a way older builds can't read.
+const WeightsVersion = 1
+
+// WeightsPath is where tuned weight profiles are kept. The hard AI reads
+// it the first time it needs weights.
+var WeightsPath = filepath.Join("files", "weights.json")
+
+// ErrNotWeights is returned when reading a file that isn't a weights file.
+var ErrNotWeights = errors.New("not a Connectron weights file")
+
+// WeightProfile is a set of weights tuned for one kind of game.
+type WeightProfile struct {
+	GridWidth     int       `json:"gridWidth"`
+	GridHeight    int       `json:"gridHeight"`
+	LineLength    int       `json:"lineLength"`
+	CornerBonus   bool      `json:"cornerBonus"`
+	SolitaireRule bool      `json:"solitaireRule"`
+	BombCounter   bool      `json:"bombCounter"`
+	OverflowRule  bool      `json:"overflowRule"`
+	Alliances     bool      `json:"alliances"`
+	Weights       Weights   `json:"weights"`
+	Score         float64   `json:"score"` // share of the points won against the weights tuning started from
+	Tuned         time.Time `json:"tuned"`
+}
+
+// ProfileFor starts a profile for the kind of game config is.
+func ProfileFor(config types.GameConfig, w Weights) WeightProfile {
+	return WeightProfile{
+		GridWidth:     config.GridWidth,
+		GridHeight:    config.GridHeight,
+		LineLength:    config.LineLength,
+		CornerBonus:   config.CornerBonus,
+		SolitaireRule: config.SolitaireRule,
+		BombCounter:   config.BombCounter,
+		OverflowRule:  config.OverflowRule,
+		Alliances:     config.EnableAlliances,
+		Weights:       w,
+	}
+}
+
+// distance says how different the game a profile was tuned for is from
+// another. Line length matters most, then the rules, then the board size.
+func (p WeightProfile) distance(other WeightProfile) int {
+	d := 1000 * abs(p.LineLength-other.LineLength)
+	for _, same := range []bool{
+		p.CornerBonus == other.CornerBonus,
+		p.SolitaireRule == other.SolitaireRule,
+		p.BombCounter == other.BombCounter,
+		p.OverflowRule == other.OverflowRule,
+		p.Alliances == other.Alliances,
+	} {
+		if !same {
+			d += 100
+		}
+	}
+	return d + abs(p.GridWidth-other.GridWidth) + abs(p.GridHeight-other.GridHeight)
+}
+
+// BestProfile picks the profile tuned for the game most like config.
+func BestProfile(profiles []WeightProfile, config types.GameConfig) (WeightProfile, bool) {
+	want := ProfileFor(config, Weights{})
+	best := -1
+	for i, profile := range profiles {
+		if best == -1 || profile.distance(want) < profiles[best].distance(want) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return WeightProfile{}, false
+	}
+	return profiles[best], true
+}
+
+// weightsFile is the layout of the weights file.
+type weightsFile struct {
+	Version  int             `json:"version"`
+	Profiles []WeightProfile `json:"profiles"`
+}
+
+// ReadWeightProfiles reads a weights file.
+func ReadWeightProfiles(filePath string) ([]WeightProfile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var file weightsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotWeights, err)
+	}
+	if file.Version != WeightsVersion {
+		return nil, fmt.Errorf("%w: unknown version %d", ErrNotWeights, file.Version)
+	}
+	return file.Profiles, nil
+}
+
+// SaveWeightProfile adds a profile to a weights file, replacing any tuned
+// for exactly the same kind of game.
+func SaveWeightProfile(filePath string, profile WeightProfile) error {
+	profiles, err := ReadWeightProfiles(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	replaced := false
+	for i := range profiles {
+		if profiles[i].distance(profile) == 0 {
+			profiles[i] = profile
+			replaced = true
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, profile)
+	}
+
+	data, err := json.MarshalIndent(weightsFile{Version: WeightsVersion, Profiles: profiles}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// The profiles the hard AI plays with, read from WeightsPath once
+var (
+	weightProfilesOnce sync.Once
+	weightProfiles     []WeightProfile
+)
+
+func loadWeightProfiles() []WeightProfile {
+	weightProfilesOnce.Do(func() {
+		var err error
+		weightProfiles, err = ReadWeightProfiles(WeightsPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Println("Error reading AI weights:", err)
+		}
+	})
+	return weightProfiles
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"golang.org/x/net/websocket"
+	"insighthub.uk/connectron/v2/network"
+)
+
+// The browser client is built into the binary so it works without internet access.
+//
+//go:embed static
+var static embed.FS
+
+// NewHandler serves the browser client at / and lets it join the hosted game
+// through a WebSocket at /ws. Browsers speak the same protocol as the desktop
+// app, one JSON message per frame.
+func NewHandler(server *network.Server) http.Handler {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // only fails if the embed directive is wrong
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(assets)))
+	mux.Handle("/ws", websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			ws.PayloadType = websocket.TextFrame
+			server.ServeConn(ws)
+		},
+	})
+	return mux
+}
+
+// checkOrigin only lets pages served by this server open a game connection.
+func checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	if origin == nil || origin.Host != req.Host {
+		return fmt.Errorf("origin %v not allowed", origin)
+	}
+	config.Origin = origin
+	return nil
+}
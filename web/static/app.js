@@ -0,0 +1,191 @@
+// Browser client for a hosted Connectron game. It speaks the same protocol
+// as the desktop app (see network/protocol.go); the host applies the rules.
+"use strict";
+
+const PROTOCOL_VERSION = 1;
+
+// How often to try getting back into the game after the connection drops
+const RECONNECT_INTERVAL = 2000;
+
+// Same order as the colours in ui.NewGame
+const COLORS = [
+	"rgb(255,0,0)", "rgb(0,255,0)", "rgb(0,0,255)", "rgb(255,255,0)", "rgb(255,0,255)",
+	"rgb(0,255,255)", "rgb(128,0,128)", "rgb(255,165,0)", "rgb(128,128,128)", "rgb(0,128,128)",
+];
+const EMPTY = "rgb(240,240,240)";
+
+let socket = null;
+let config = null;
+let seats = [];
+let state = null;
+let token = sessionStorage.getItem("connectronToken");
+let welcomed = false; // whether the host has accepted the current connection
+
+const info = document.getElementById("info");
+const board = document.getElementById("board");
+
+document.getElementById("chatForm").addEventListener("submit", (event) => {
+	event.preventDefault();
+	sendChat();
+});
+document.getElementById("mute").addEventListener("change", (event) => {
+	document.getElementById("chatLog").hidden = event.target.checked;
+});
+document.getElementById("joinButton").addEventListener("click", () => connect(false, false));
+document.getElementById("watchButton").addEventListener("click", () => connect(false, true));
+
+// Coming back to the page (or reloading it) picks up the game where we left off
+if (token) {
+	connect(true, false);
+}
+
+// connect joins the game, or watches it without a seat when watch is true.
+// When resuming, the saved token reclaims the seats we had before.
+function connect(resuming, watch) {
+	const scheme = location.protocol === "https:" ? "wss:" : "ws:";
+	socket = new WebSocket(scheme + "//" + location.host + "/ws");
+	welcomed = false;
+	socket.onopen = () => send({
+		type: "hello", version: PROTOCOL_VERSION, name: document.getElementById("name").value,
+		watch: watch, token: resuming ? token : undefined,
+	});
+	socket.onmessage = (event) => handle(JSON.parse(event.data));
+	socket.onclose = () => {
+		if (!token) {
+			info.textContent = "Disconnected from the host!";
+			return;
+		}
+		info.textContent = "Connection lost, reconnecting...";
+		setTimeout(() => connect(true, false), RECONNECT_INTERVAL);
+	};
+}
+
+function send(message) {
+	socket.send(JSON.stringify(message));
+}
+
+function handle(message) {
+	switch (message.type) {
+	case "welcome":
+		config = message.config;
+		seats = message.seats || [];
+		welcomed = true;
+		saveToken(message.token);
+		document.getElementById("join").hidden = true;
+		document.getElementById("game").hidden = false;
+		document.getElementById("bomb").disabled = !config.bombCounter || seats.length === 0;
+		document.getElementById("chatForm").hidden = seats.length === 0;
+		document.getElementById("allianceOnly").hidden = !config.enableAlliances;
+		buildBoard();
+		update(message.state);
+		// Everything said so far, including while we were disconnected
+		document.getElementById("chatLog").innerHTML = "";
+		for (const chat of message.chatHistory || []) {
+			showChat(chat);
+		}
+		break;
+	case "chat":
+		showChat(message.chat);
+		break;
+	case "state":
+		update(message.state);
+		break;
+	case "delta":
+		// Spectators only get the cells that changed
+		message.state.grid = state.grid.map((row) => row.slice());
+		for (const cell of message.cells || []) {
+			message.state.grid[cell.r][cell.c] = cell.p;
+		}
+		update(message.state);
+		break;
+	case "error":
+		if (!welcomed) {
+			// Refused, so there is no seat to get back to
+			saveToken(null);
+			document.getElementById("join").hidden = false;
+		}
+		info.textContent = message.error;
+		break;
+	}
+}
+
+function saveToken(newToken) {
+	token = newToken || null;
+	if (token) {
+		sessionStorage.setItem("connectronToken", token);
+	} else {
+		sessionStorage.removeItem("connectronToken");
+	}
+}
+
+// sendChat says what's in the chat box as whichever of our players is moving
+function sendChat() {
+	const input = document.getElementById("chatText");
+	const text = input.value.trim();
+	if (text === "" || seats.length === 0) {
+		return;
+	}
+	const player = state && seats.includes(state.currentTurn) ? state.currentTurn : seats[0];
+	send({ type: "chat", chat: { player: player, text: text, alliance: document.getElementById("alliance").checked } });
+	input.value = "";
+}
+
+function showChat(chat) {
+	const log = document.getElementById("chatLog");
+	const line = document.createElement("div");
+	let speaker = chat.name ? chat.name + " (Player " + (chat.player + 1) + ")" : "Player " + (chat.player + 1);
+	if (chat.alliance) {
+		speaker = "[Alliance] " + speaker;
+		line.className = "alliance";
+	}
+	line.textContent = speaker + ": " + chat.text;
+	log.appendChild(line);
+	log.scrollTop = log.scrollHeight;
+}
+
+function buildBoard() {
+	board.style.gridTemplateColumns = "repeat(" + config.gridWidth + ", auto)";
+	board.innerHTML = "";
+	for (let row = 0; row < config.gridHeight; row++) {
+		for (let col = 0; col < config.gridWidth; col++) {
+			const cell = document.createElement("div");
+			cell.className = "cell";
+			cell.addEventListener("click", () => drop(col));
+			board.appendChild(cell);
+		}
+	}
+}
+
+function drop(column) {
+	if (!state || state.roundOver || !seats.includes(state.currentTurn)) {
+		info.textContent = "It's not your turn!";
+		return;
+	}
+	const bomb = document.getElementById("bomb");
+	send({ type: "move", move: { player: state.currentTurn, column: column, bomb: bomb.checked } });
+	bomb.checked = false;
+}
+
+function update(newState) {
+	state = newState;
+	const cells = board.children;
+	for (let row = 0; row < config.gridHeight; row++) {
+		for (let col = 0; col < config.gridWidth; col++) {
+			const player = state.grid[row][col];
+			cells[row * config.gridWidth + col].style.background = player === -1 ? EMPTY : COLORS[player];
+		}
+	}
+	info.textContent = statusText();
+	const watching = state.spectators || 0;
+	document.getElementById("spectators").textContent = watching === 0 ? "" : watching + (watching === 1 ? " spectator" : " spectators") + " watching";
+}
+
+function statusText() {
+	if (state.roundOver) {
+		const winner = state.winners[state.winners.length - 1];
+		const result = winner === 0 ? "The game is a draw!" : "Player " + winner + " Wins!";
+		return state.seriesOver ? result + " Series over." : result;
+	}
+	const turn = "Player " + (state.currentTurn + 1) + "'s Turn";
+	return seats.includes(state.currentTurn) ? turn + " (you)" : turn;
+}